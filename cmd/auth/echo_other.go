@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package auth
+
+import "errors"
+
+// disableEcho is not supported on this platform, so passwords are read visibly.
+func disableEcho(fd uintptr) (func(), error) {
+	return nil, errors.New("hiding input is not supported on this platform")
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	appauth "github.com/dydx/vico-cli/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+// loginCmd represents the command to log in and cache a new token.
+// Credentials that are not configured are prompted for.
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to a Vicohome account",
+	Long: `Log in to a Vicohome account and cache the token for later commands.
+
+The email and password are taken from VICOHOME_EMAIL and VICOHOME_PASSWORD or
+the selected profile. Missing values are prompted for; the password is not
+shown while it is typed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseURL, err := cmdutil.BaseURL()
+		if err != nil {
+			return err
+		}
+
+		email, password := cmdutil.Credentials()
+		reader := bufio.NewReader(os.Stdin)
+		if email == "" {
+			fmt.Print("Email: ")
+			if email, err = readLine(reader); err != nil {
+				fmt.Println()
+				return fmt.Errorf("error reading email: %w", err)
+			}
+		}
+		if password == "" {
+			fmt.Print("Password: ")
+			password, err = readPassword(reader)
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("error reading password: %w", err)
+			}
+		}
+		if email == "" || password == "" {
+			return &appauth.AuthError{Msg: "email and password are required"}
+		}
+
+		source := appauth.NewTokenSource(baseURL)
+		source.Email, source.Password = email, password
+		source.Retry = cmdutil.RetryPolicy()
+
+		ctx, cancel := cmdutil.Context(cmd)
+		defer cancel()
+		if _, err := source.Refresh(ctx); err != nil {
+			return fmt.Errorf("error logging in: %w", err)
+		}
+
+		fmt.Printf("Logged in as %s.\n", email)
+		return nil
+	},
+}
+
+// readLine reads a line of input without its line ending.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPassword reads a line of input without echoing it, if stdin is a terminal.
+// The terminal is restored if the user interrupts the prompt.
+func readPassword(reader *bufio.Reader) (string, error) {
+	restore, err := disableEcho(os.Stdin.Fd())
+	if err != nil {
+		// Not a terminal, e.g. a password piped in by a script
+		return readLine(reader)
+	}
+	defer restore()
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(interrupted)
+		close(interrupted)
+	}()
+	go func() {
+		if _, ok := <-interrupted; ok {
+			restore()
+			fmt.Println()
+			os.Exit(130)
+		}
+	}()
+
+	return readLine(reader)
+}
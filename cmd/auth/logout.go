@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/dydx/vico-cli/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var logoutAll bool
+
+// logoutCmd represents the command to discard cached tokens.
+// It removes the token of the active account and endpoint, or every cached token with --all.
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Discard the cached token",
+	Long: `Remove the cached authentication token of the active account and endpoint.
+The next command logs in again. Use --all to remove the tokens of every account.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cacheManager, err := newCacheManager()
+		if err != nil {
+			return err
+		}
+
+		if logoutAll {
+			if len(cacheManager.List()) == 0 {
+				fmt.Println("Not logged in.")
+				return nil
+			}
+			if err := cacheManager.ClearAll(); err != nil {
+				return err
+			}
+			fmt.Println("Logged out of all accounts.")
+			return nil
+		}
+
+		cached, ok := cacheManager.Load()
+		if !ok {
+			fmt.Println("Not logged in.")
+			return nil
+		}
+
+		if err := cacheManager.ClearToken(); err != nil {
+			return err
+		}
+		fmt.Printf("Logged out %s.\n", accountName(cached))
+		return nil
+	},
+}
+
+// newCacheManager returns a token cache manager for the account and endpoint selected
+// by the global options, the profile and the environment.
+func newCacheManager() (*cache.TokenCacheManager, error) {
+	baseURL, err := cmdutil.BaseURL()
+	if err != nil {
+		return nil, err
+	}
+	email, _ := cmdutil.Credentials()
+	return cache.NewAccountTokenCacheManager(email, baseURL)
+}
+
+// accountName returns the email address of a cached token's account for display.
+func accountName(cached cache.TokenCache) string {
+	if cached.Email == "" {
+		return "(unknown)"
+	}
+	return cached.Email
+}
+
+func init() {
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Remove the cached tokens of all accounts and endpoints")
+}
@@ -0,0 +1,29 @@
+// Package auth implements commands for managing the CLI's authentication.
+//
+// This package provides commands for logging in to a Vicohome account, logging out
+// by discarding the cached token, and showing the state of the cached token.
+package auth
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage authentication",
+	Long:  `Log in to a Vicohome account, log out, and show the state of the cached token.`,
+}
+
+func init() {
+	// Add subcommands
+	authCmd.AddCommand(loginCmd)
+	authCmd.AddCommand(logoutCmd)
+	authCmd.AddCommand(statusCmd)
+}
+
+// GetAuthCmd returns the auth command that provides access to authentication subcommands.
+// This function is called by the root command to add authentication functionality to the CLI.
+// It returns the auth command with all subcommands (login, logout, status) already attached.
+func GetAuthCmd() *cobra.Command {
+	return authCmd
+}
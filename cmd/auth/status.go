@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	appauth "github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/cache"
+	"github.com/dydx/vico-cli/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the command to show the state of the active account's cached token.
+// It checks whether the API still accepts the token without logging in again, and lists
+// the other accounts with cached tokens.
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cached account and token state",
+	Long: `Show which account the cached token of the active account and endpoint belongs
+to, when it expires, and whether the API still accepts it. The token is not refreshed
+by this command. Other accounts with cached tokens are listed afterwards.
+
+The command fails with the authentication exit code if no valid token is cached for
+the account or the API does not accept it. If the API cannot be reached or reports
+another error, the token's state is unknown and the command fails with the exit code
+of that error.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseURL, err := cmdutil.BaseURL()
+		if err != nil {
+			return err
+		}
+
+		cacheManager, err := newCacheManager()
+		if err != nil {
+			return err
+		}
+
+		cached, ok := cacheManager.Load()
+		defer printOtherAccounts(cacheManager, cached)
+		if !ok {
+			fmt.Println("Not logged in.")
+			return &appauth.AuthError{Msg: "not logged in"}
+		}
+
+		fmt.Printf("Account:  %s\n", accountName(cached))
+		fmt.Printf("Endpoint: %s\n", baseURL)
+
+		expires := cached.ExpiresAt.In(cmdutil.Location()).Format("2006-01-02 15:04:05 MST")
+		remaining := time.Until(cached.ExpiresAt)
+		if remaining <= 0 {
+			fmt.Printf("Expires:  %s (expired)\n", expires)
+			fmt.Println("API:      not checked, token has expired")
+			return &appauth.AuthError{Msg: "token has expired"}
+		}
+		precision := time.Minute
+		if remaining < time.Minute {
+			precision = time.Second
+		}
+		fmt.Printf("Expires:  %s (in %s)\n", expires, remaining.Round(precision))
+
+		// Check the token with a cheap request that cannot trigger a refresh
+		c := client.New(client.StaticToken(cached.Token))
+		c.BaseURL = baseURL
+		c.Retry = cmdutil.RetryPolicy()
+
+		ctx, cancel := cmdutil.Context(cmd)
+		defer cancel()
+		if _, err := c.ListDevices(ctx); err != nil {
+			var authErr *appauth.AuthError
+			var networkErr *appauth.NetworkError
+			switch {
+			case errors.As(err, &authErr):
+				fmt.Println("API:      token not accepted")
+			case errors.As(err, &networkErr), ctx.Err() != nil:
+				fmt.Println("API:      not checked, the API could not be reached")
+			default:
+				fmt.Println("API:      not checked, the API reported an error")
+			}
+			return err
+		}
+		fmt.Println("API:      token accepted")
+		return nil
+	},
+}
+
+// printOtherAccounts lists the cached tokens other than active.
+func printOtherAccounts(cacheManager *cache.TokenCacheManager, active cache.TokenCache) {
+	var others []cache.TokenCache
+	for _, cached := range cacheManager.List() {
+		if cache.AccountKey(cached.Email, cached.BaseURL) != cache.AccountKey(active.Email, active.BaseURL) {
+			others = append(others, cached)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Other cached accounts:")
+	for _, cached := range others {
+		state := "valid"
+		if time.Now().After(cached.ExpiresAt) {
+			state = "expired"
+		}
+		fmt.Printf("  %s  %s  (%s)\n", accountName(cached), cached.BaseURL, state)
+	}
+}
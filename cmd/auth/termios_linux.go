@@ -0,0 +1,11 @@
+//go:build linux
+
+package auth
+
+import "syscall"
+
+// ioctl requests that read and write terminal attributes.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
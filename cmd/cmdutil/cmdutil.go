@@ -0,0 +1,243 @@
+// Package cmdutil holds the global options and helpers shared by the CLI's
+// command packages.
+//
+// Global flags are registered on the root command by AddGlobalFlags and read
+// by subcommands through helpers such as NewClient, so that every command
+// talks to the API in the same way.
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/client"
+	"github.com/dydx/vico-cli/pkg/logging"
+	"github.com/dydx/vico-cli/pkg/output"
+	"github.com/dydx/vico-cli/pkg/output/stdout"
+	"github.com/dydx/vico-cli/pkg/ratelimit"
+	"github.com/dydx/vico-cli/pkg/recorder"
+	"github.com/dydx/vico-cli/pkg/retry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	region     string
+	apiURL     string
+	maxRetries int
+	rateLimit  float64
+	timeout    time.Duration
+	recordDir  string
+	replayDir  string
+	logLevel   string
+	logFormat  string
+
+	outputFormat   string
+	outputColumns  []string
+	outputTemplate string
+	noHeaders      bool
+	sortBy         string
+	reverse        bool
+)
+
+// AddGlobalFlags registers the global options as persistent flags on the root command.
+// Defaults are taken from the VICOHOME_* environment variables named in the flag help,
+// and from the configuration profile applied by ApplyConfig.
+func AddGlobalFlags(root *cobra.Command) {
+	addConfigFlags(root)
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&region, "region", envOrDefault("VICOHOME_REGION", client.DefaultRegion), "API region (us or eu) [env VICOHOME_REGION]")
+	flags.StringVar(&apiURL, "api-url", os.Getenv("VICOHOME_API_URL"), "Custom API base URL, overrides --region [env VICOHOME_API_URL]")
+	flags.IntVar(&maxRetries, "max-retries", envInt("VICOHOME_MAX_RETRIES", DefaultMaxRetries), "Times a request failing with a timeout, reset connection or 408/429/5xx status is retried [env VICOHOME_MAX_RETRIES]")
+	flags.Float64Var(&rateLimit, "rate-limit", envFloat("VICOHOME_RATE_LIMIT", DefaultRateLimit), "Maximum API and download requests per second, 0 for no limit [env VICOHOME_RATE_LIMIT]")
+	flags.DurationVar(&timeout, "timeout", envDuration("VICOHOME_TIMEOUT", 0), "Time limit for the whole command, e.g. 30s or 5m, 0 for no limit [env VICOHOME_TIMEOUT]")
+	flags.StringVar(&recordDir, "record", os.Getenv("VICOHOME_RECORD"), "Save sanitized API requests and responses into this directory [env VICOHOME_RECORD]")
+	flags.StringVar(&replayDir, "replay", os.Getenv("VICOHOME_REPLAY"), "Answer API requests from a directory written by --record instead of the network [env VICOHOME_REPLAY]")
+	flags.StringVar(&logLevel, "log-level", envOrDefault("VICOHOME_LOG_LEVEL", defaultLogLevel()), "Lowest level of log messages written to stderr: debug, info, warn or error [env VICOHOME_LOG_LEVEL]")
+	flags.StringVar(&logFormat, "log-format", envOrDefault("VICOHOME_LOG_FORMAT", logging.FormatText), "Format of log messages: text or json [env VICOHOME_LOG_FORMAT]")
+}
+
+// Defaults of the --max-retries and --rate-limit flags.
+const (
+	DefaultMaxRetries = 3  // Retries after the first attempt of a request
+	DefaultRateLimit  = 10 // Requests per second
+)
+
+// RetryPolicy returns the retry policy selected by the --max-retries flag.
+func RetryPolicy() retry.Policy {
+	policy := retry.DefaultPolicy()
+	policy.MaxAttempts = maxRetries + 1
+	return policy
+}
+
+// NewRateLimiter returns a rate limiter for the rate selected by the --rate-limit flag,
+// or nil if the rate is not limited. Bursts of up to one second's worth of requests
+// are allowed.
+func NewRateLimiter() *ratelimit.Limiter {
+	return ratelimit.New(rateLimit, int(math.Ceil(rateLimit)))
+}
+
+// Context returns the context that a command's requests run in: the command's
+// context, which is canceled on SIGINT or SIGTERM, limited by the --timeout flag.
+// The returned cancel function must be called when the command is done.
+//
+// Parameters:
+//   - cmd: The running command
+//
+// Returns:
+//   - context.Context: The context to pass to the client and downloader
+//   - context.CancelFunc: Releases the context's resources
+func Context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// BaseURL returns the API base URL selected by the global options and the profile.
+func BaseURL() (string, error) {
+	return client.ResolveBaseURL(region, apiURL)
+}
+
+// NewClient creates an API client configured from the global options and the
+// selected profile. Login and token refresh are sent to the same base URL as the
+// API requests.
+//
+// With --record, every API exchange including logins is saved to the recording
+// directory. With --replay, requests are answered from a recording and neither the
+// network nor the token cache is used.
+//
+// Returns:
+//   - *client.Client: The configured client
+//   - error: An error if the region, API URL or recording options are invalid
+func NewClient() (*client.Client, error) {
+	baseURL, err := BaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	source := auth.NewTokenSource(baseURL)
+	source.Email, source.Password = Credentials()
+	source.Retry = RetryPolicy()
+
+	c := client.New(source)
+	c.BaseURL = baseURL
+	c.Retry = RetryPolicy()
+	c.Limiter = NewRateLimiter()
+
+	switch {
+	case recordDir != "" && replayDir != "":
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	case recordDir != "":
+		rec, err := recorder.NewRecorder(recordDir, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.HTTPClient.Transport = rec
+		source.HTTPClient = &http.Client{Timeout: auth.RequestTimeout, Transport: rec}
+	case replayDir != "":
+		replayer, err := recorder.NewReplayer(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		c.HTTPClient.Transport = replayer
+		// Recorded tokens are redacted, so any token will do
+		c.Auth = client.StaticToken(recorder.Redacted)
+		c.Limiter = nil
+	}
+	c.Location = Location()
+	if profile.Language != "" {
+		c.Language = profile.Language
+	}
+	if profile.Country != "" {
+		c.CountryNo = profile.Country
+	}
+	return c, nil
+}
+
+// AddOutputFlags registers the flags that control how cmd writes its results.
+// The --format help text lists every format in the output registry.
+func AddOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFormat, "format", "table", fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", ")))
+	cmd.Flags().StringSliceVar(&outputColumns, "columns", nil, "Comma-separated columns to write for table, csv and tsv output")
+	cmd.Flags().StringVar(&outputTemplate, "template", "", "Go template for template output, e.g. '{{.TraceID}}'")
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "Omit header rows from table, csv and tsv output")
+}
+
+// AddListOutputFlags registers the output flags of AddOutputFlags on cmd, together
+// with the flags that control the order of listed items.
+func AddListOutputFlags(cmd *cobra.Command) {
+	AddOutputFlags(cmd)
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "Column to sort by, e.g. timestamp, bird, confidence or device")
+	cmd.Flags().BoolVar(&reverse, "reverse", false, "Sort in descending order, or reverse the default order")
+}
+
+// NewOutput creates the output handler selected by the output flags. It is called
+// before any request is sent, so that invalid flags are reported right away.
+//
+// Parameters:
+//   - kind: The kind of records the command writes, output.KindEvents or output.KindDevices
+//   - emptyMessage: The message shown for an empty list, or "" for the format's default
+//
+// Returns:
+//   - output.Handler: The handler for the selected format
+//   - error: An error if the format is invalid, or the columns or sort column are not
+//     columns of kind
+func NewOutput(kind, emptyMessage string) (output.Handler, error) {
+	return output.Factory(outputFormat, output.Options{
+		Kind:         kind,
+		EmptyMessage: emptyMessage,
+		Columns:      outputColumns,
+		Template:     outputTemplate,
+		NoHeaders:    noHeaders,
+		Width:        stdout.TerminalWidth(os.Stdout),
+		SortBy:       sortBy,
+		Reverse:      reverse,
+	})
+}
+
+// envOrDefault returns the value of the environment variable key, or fallback if it is unset or empty.
+func envOrDefault(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
+
+// envInt returns the integer value of the environment variable key, or fallback if it
+// is unset or not an integer.
+func envInt(key string, fallback int) int {
+	if val, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return val
+	}
+	return fallback
+}
+
+// envFloat returns the numeric value of the environment variable key, or fallback if
+// it is unset or not a number.
+func envFloat(key string, fallback float64) float64 {
+	if val, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return val
+	}
+	return fallback
+}
+
+// envDuration returns the duration in the environment variable key, or fallback if it
+// is unset or not a duration.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if val, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return val
+	}
+	return fallback
+}
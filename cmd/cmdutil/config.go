@@ -0,0 +1,114 @@
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cfgFile     string
+	profileName string
+
+	// Settings of the selected profile, set by ApplyConfig
+	profile  = &config.Profile{}
+	location = time.Local
+)
+
+// addConfigFlags registers the flags that select the configuration file and profile.
+func addConfigFlags(root *cobra.Command) {
+	flags := root.PersistentFlags()
+	flags.StringVar(&cfgFile, "config", os.Getenv("VICOHOME_CONFIG"), "Config file (default ~/.vicohome/config.yaml) [env VICOHOME_CONFIG]")
+	flags.StringVar(&profileName, "profile", os.Getenv("VICOHOME_PROFILE"), "Config profile to use (default is the current profile) [env VICOHOME_PROFILE]")
+}
+
+// ConfigPath returns the location of the configuration file selected by --config.
+func ConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.DefaultPath()
+}
+
+// SelectedProfile returns the profile name given with --profile, or "" if none was given.
+func SelectedProfile() string {
+	return profileName
+}
+
+// ApplyConfig loads the configuration file and applies the selected profile to the
+// options of cmd. Settings are taken from command line flags first, then from
+// environment variables, then from the profile, and finally from built-in defaults.
+// The region and API URL select the endpoint together: if either is given on the
+// command line or in the environment, neither is taken from the profile, so that
+// --region is not overridden by a profile's api-url.
+//
+// Parameters:
+//   - cmd: The command being executed
+//
+// Returns:
+//   - error: An error if the file is malformed or the selected profile does not exist
+func ApplyConfig(cmd *cobra.Command) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	name := cfg.ProfileName(profileName)
+	selected, ok := cfg.Profiles[name]
+	if !ok {
+		if profileName != "" {
+			return fmt.Errorf("profile %q not found in %s", profileName, path)
+		}
+		selected = &config.Profile{}
+	}
+	profile = selected
+
+	flags := cmd.Flags()
+	regionGiven := flags.Changed("region") || os.Getenv("VICOHOME_REGION") != ""
+	apiURLGiven := flags.Changed("api-url") || os.Getenv("VICOHOME_API_URL") != ""
+	if !regionGiven && !apiURLGiven {
+		if profile.Region != "" {
+			region = profile.Region
+		}
+		if profile.APIURL != "" {
+			apiURL = profile.APIURL
+		}
+	}
+	if flags.Lookup("format") != nil && !flags.Changed("format") && profile.Format != "" {
+		outputFormat = profile.Format
+	}
+
+	location, err = profile.Location()
+	return err
+}
+
+// Location returns the time zone of the selected profile, or the local time zone.
+// Event times are shown, and time flags are read, in this time zone.
+func Location() *time.Location {
+	return location
+}
+
+// Credentials returns the account email and password selected by the environment
+// and the profile. The VICOHOME_EMAIL and VICOHOME_PASSWORD pair is used if
+// VICOHOME_EMAIL is set or the profile has no email. Otherwise the profile's email
+// is used with the password from the environment variable named by its
+// password-env setting, or from VICOHOME_PASSWORD. Values that are not configured
+// are returned empty.
+func Credentials() (string, string) {
+	if email := os.Getenv("VICOHOME_EMAIL"); email != "" || profile.Email == "" {
+		return email, os.Getenv("VICOHOME_PASSWORD")
+	}
+
+	passwordEnv := profile.PasswordEnv
+	if passwordEnv == "" {
+		passwordEnv = "VICOHOME_PASSWORD"
+	}
+	return profile.Email, os.Getenv(passwordEnv)
+}
@@ -0,0 +1,54 @@
+package cmdutil
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+)
+
+// Exit codes of the CLI. Scripts can rely on these instead of parsing error messages.
+const (
+	ExitOK       = 0 // The command succeeded
+	ExitError    = 1 // Any other failure, such as invalid flags, arguments or configuration
+	ExitAuth     = 2 // Authentication failed: missing or rejected credentials or token
+	ExitNotFound = 3 // The requested device or event does not exist
+	ExitAPI      = 4 // The API returned an error result
+	ExitNetwork  = 5 // The API could not be reached, did not respond in time, or the response could not be read
+
+	ExitInterrupted = 130 // The command was interrupted by SIGINT or SIGTERM, as shells report for Ctrl-C
+)
+
+// ExitCode returns the exit code for an error returned by a command.
+// Interruptions take precedence, followed by network failures and timeouts, so that a
+// login that fails because the API is unreachable is reported as a network error
+// rather than an authentication failure.
+//
+// Parameters:
+//   - err: The error returned by the command, or nil
+//
+// Returns:
+//   - int: One of the Exit* codes
+func ExitCode(err error) int {
+	var networkErr *auth.NetworkError
+	var authErr *auth.AuthError
+	var notFoundErr *auth.NotFoundError
+	var apiErr *auth.APIError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.As(err, &networkErr), errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork
+	case errors.As(err, &authErr):
+		return ExitAuth
+	case errors.As(err, &notFoundErr):
+		return ExitNotFound
+	case errors.As(err, &apiErr):
+		return ExitAPI
+	default:
+		return ExitError
+	}
+}
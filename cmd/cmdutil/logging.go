@@ -0,0 +1,36 @@
+package cmdutil
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/dydx/vico-cli/pkg/logging"
+)
+
+// defaultLogLevel returns the default of the --log-level flag: warn, or debug when
+// the older VICOHOME_DEBUG variable is set.
+func defaultLogLevel() string {
+	if debug := os.Getenv("VICOHOME_DEBUG"); debug == "1" || debug == "true" {
+		return "debug"
+	}
+	return "warn"
+}
+
+// SetupLogging installs the logger selected by the --log-level and --log-format flags,
+// writing to stderr, for the packages of this module and as the slog default.
+//
+// Returns:
+//   - error: An error if the level or format is unknown
+func SetupLogging() error {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	logger, err := logging.New(os.Stderr, level, logFormat)
+	if err != nil {
+		return err
+	}
+	logging.SetLogger(logger)
+	slog.SetDefault(logger)
+	return nil
+}
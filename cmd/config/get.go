@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	appconfig "github.com/dydx/vico-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// getCmd represents the command to print a single setting of the selected profile.
+var getCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print a setting of a profile",
+	Long: `Print the value of a setting of the selected profile, or the name of the
+current profile for the key current-profile. Unset settings print an empty line.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+
+		cfg, _, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		if key == appconfig.CurrentProfileKey {
+			fmt.Println(cfg.CurrentProfile)
+			return nil
+		}
+
+		profile, ok := cfg.Profiles[cfg.ProfileName(cmdutil.SelectedProfile())]
+		if !ok {
+			profile = &appconfig.Profile{}
+		}
+		value, err := profile.Get(key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
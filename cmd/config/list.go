@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	appconfig "github.com/dydx/vico-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// listCmd represents the command to print every profile and its settings.
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all profiles and their settings",
+	Long:  `Print the location of the configuration file and the settings of every profile, marking the current profile with *.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Config file: %s\n", path)
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			fmt.Println("No profiles configured.")
+			return nil
+		}
+
+		current := cfg.ProfileName("")
+		for _, name := range names {
+			marker := " "
+			if name == current {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+
+			profile := cfg.Profiles[name]
+			for _, key := range appconfig.Keys {
+				if value, _ := profile.Get(key); value != "" {
+					fmt.Printf("    %-13s %s\n", key+":", value)
+				}
+			}
+		}
+
+		return nil
+	},
+}
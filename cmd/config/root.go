@@ -0,0 +1,63 @@
+// Package config implements commands for viewing and changing the CLI's configuration file.
+//
+// This package provides commands for listing the configured profiles and for reading
+// and writing individual settings of a profile.
+package config
+
+import (
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	appconfig "github.com/dydx/vico-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage configuration profiles",
+	Long: `View and change the settings stored in the configuration file.
+
+Settings belong to a named profile, selected with --profile or by the
+current-profile setting. Available settings are:
+  email          Account email address
+  password-env   Environment variable holding the account password
+  region         API region (us or eu)
+  api-url        Custom API base URL, overrides region
+  format         Default output format
+  timezone       Time zone for event times, e.g. Europe/Berlin
+  language       Language code sent with requests, e.g. en
+  country        Country code sent with requests, e.g. US`,
+	// The config commands read the file themselves, without applying a profile,
+	// so that they keep working while the file refers to a missing profile.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Errors from here on are not caused by command line usage
+		cmd.SilenceUsage = true
+		return cmdutil.SetupLogging()
+	},
+}
+
+func init() {
+	// Add subcommands
+	configCmd.AddCommand(getCmd)
+	configCmd.AddCommand(setCmd)
+	configCmd.AddCommand(listCmd)
+}
+
+// GetConfigCmd returns the config command that provides access to configuration subcommands.
+// This function is called by the root command to add configuration functionality to the CLI.
+// It returns the config command with all subcommands (get, set, list) already attached.
+func GetConfigCmd() *cobra.Command {
+	return configCmd
+}
+
+// loadConfig reads the configuration file selected by --config.
+// It returns the configuration and the file's location.
+func loadConfig() (*appconfig.Config, string, error) {
+	path, err := cmdutil.ConfigPath()
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := appconfig.Load(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, path, nil
+}
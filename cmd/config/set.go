@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	appconfig "github.com/dydx/vico-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the command to change a single setting of the selected profile.
+var setCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Change a setting of a profile",
+	Long: `Change a setting of the selected profile, creating the profile and the
+configuration file if needed. An empty value unsets the setting. Use the key
+current-profile to choose the profile used when --profile is not given.
+
+Values are checked before they are saved: region must be a known region, api-url
+an absolute URL, format a supported output format and timezone a known time zone.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+
+		cfg, path, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := cfg.ProfileName(cmdutil.SelectedProfile())
+		if key == appconfig.CurrentProfileKey {
+			cfg.CurrentProfile = value
+		} else if err := cfg.Profile(name).Set(key, value); err != nil {
+			return err
+		}
+
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+
+		if key == appconfig.CurrentProfileKey {
+			fmt.Printf("Current profile set to %q.\n", value)
+		} else {
+			fmt.Printf("Set %s to %q in profile %q.\n", key, value, name)
+		}
+
+		return nil
+	},
+}
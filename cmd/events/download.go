@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/dydx/vico-cli/pkg/download"
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	downloadMedia    bool
+	downloadDir      string
+	downloadTemplate string
+	downloadKinds    string
+)
+
+// downloadCmd represents the command to download the media of specific events.
+// It saves the image, keyshot and video of each event into a directory.
+var downloadCmd = &cobra.Command{
+	Use:   "download [traceID...]",
+	Short: "Download media for specific events",
+	Long: `Download the image, keyshot and video of one or more events by trace ID.
+
+Files are named using --filename-template, which supports the placeholders
+{date}, {time}, {device}, {serial}, {bird}, {traceId}, {kind} and {ext}.
+Files that already exist are skipped and interrupted downloads are resumed,
+except HLS videos, which are fetched again from the start.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		downloader, err := newDownloader()
+		if err != nil {
+			return err
+		}
+
+		c, err := cmdutil.NewClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := cmdutil.Context(cmd)
+		defer cancel()
+
+		for _, traceID := range args {
+			event, err := c.GetEvent(ctx, traceID)
+			if err != nil {
+				return fmt.Errorf("error fetching event %s: %w", traceID, err)
+			}
+
+			if err := downloadEvents(ctx, downloader, []models.Event{event}, os.Stdout); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addDownloadFlags(downloadCmd)
+}
+
+// addDownloadFlags registers the flags that control where and which media is saved.
+func addDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&downloadDir, "download-dir", ".", "Directory to save downloaded media into")
+	cmd.Flags().StringVar(&downloadTemplate, "filename-template", download.DefaultTemplate, "Template for downloaded file names, relative to --download-dir")
+	cmd.Flags().StringVar(&downloadKinds, "media", strings.Join(download.AllKinds, ","), "Comma-separated media kinds to download (image, keyshot, video)")
+}
+
+// newDownloader creates a downloader from the download flags and validates it.
+func newDownloader() (*download.Downloader, error) {
+	downloader := download.New(downloadDir, downloadTemplate)
+	downloader.Retry = cmdutil.RetryPolicy()
+	downloader.Limiter = cmdutil.NewRateLimiter()
+
+	var kinds []string
+	for _, kind := range strings.Split(downloadKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, strings.ToLower(kind))
+		}
+	}
+	downloader.Kinds = kinds
+
+	if err := downloader.Validate(); err != nil {
+		return nil, err
+	}
+	return downloader, nil
+}
+
+// downloadEvents downloads the media of each event, reporting every file to w.
+// If a download fails or ctx is done, the files saved so far are still summarized.
+func downloadEvents(ctx context.Context, downloader *download.Downloader, events []models.Event, w io.Writer) error {
+	saved, skipped := 0, 0
+	for _, event := range events {
+		results, err := downloader.DownloadEvent(ctx, event)
+		for _, result := range results {
+			if result.Skipped {
+				skipped++
+				fmt.Fprintf(w, "Skipped %s (already exists)\n", result.Path)
+			} else {
+				saved++
+				fmt.Fprintf(w, "Saved %s (%d bytes)\n", result.Path, result.Bytes)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(w, "Downloaded %d files, skipped %d existing files before stopping.\n", saved, skipped)
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Downloaded %d files, skipped %d existing files.\n", saved, skipped)
+	return nil
+}
@@ -19,11 +19,12 @@ func init() {
 	eventsCmd.AddCommand(listCmd)
 	eventsCmd.AddCommand(getCmd)
 	eventsCmd.AddCommand(searchCmd)
+	eventsCmd.AddCommand(downloadCmd)
 }
 
 // GetEventsCmd returns the events command that provides access to event-related subcommands.
 // This function is called by the root command to add event functionality to the CLI.
-// It returns the events command with all subcommands (list, get, search) already attached.
+// It returns the events command with all subcommands (list, get, search, download) already attached.
 func GetEventsCmd() *cobra.Command {
 	return eventsCmd
 }
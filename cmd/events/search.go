@@ -1,12 +1,15 @@
 package events
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
-	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/dydx/vico-cli/pkg/download"
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/dydx/vico-cli/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -19,17 +22,15 @@ var (
 
 // searchCmd represents the command to search for events that match specific criteria.
 // It allows filtering events by field values (such as device name or bird name)
-// within a specified time range, and supports output in both table and JSON formats.
+// within a specified time range, and supports output in any registered output format.
 var searchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "Search events by field value",
 	Long: `Search for events that match a specific field value within a specified time range.
 Times should be in format: 2025-05-18 14:59:25`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if searchField == "" {
-			fmt.Println("Error: --field flag is required")
-			cmd.Help()
-			return
+			return fmt.Errorf("--field flag is required")
 		}
 
 		if searchTerm == "" && len(args) > 0 {
@@ -37,76 +38,66 @@ Times should be in format: 2025-05-18 14:59:25`,
 		}
 
 		if searchTerm == "" {
-			fmt.Println("Error: search term is required")
-			cmd.Help()
-			return
+			return fmt.Errorf("search term is required (use --value or pass it as an argument)")
 		}
 
-		// Parse and validate time parameters
-		start, end, err := parseTimeParameters(searchStartTime, searchEndTime)
-		if err != nil {
-			fmt.Printf("Error parsing time parameters: %v\n", err)
-			return
+		if strings.EqualFold(searchField, "category") {
+			if err := models.CheckCategory(searchTerm); err != nil {
+				return fmt.Errorf("invalid search value: %w", err)
+			}
 		}
 
-		token, err := auth.Authenticate()
+		// Parse and validate time parameters
+		start, end, err := timeRange(cmd, searchStartTime, searchEndTime)
 		if err != nil {
-			fmt.Printf("Authentication failed: %v\n", err)
-			return
+			return fmt.Errorf("error parsing time parameters: %w", err)
 		}
 
-		startTimestamp := fmt.Sprintf("%d", start.Unix())
-		endTimestamp := fmt.Sprintf("%d", end.Unix())
-
-		eventsReq := Request{
-			StartTimestamp: startTimestamp,
-			EndTimestamp:   endTimestamp,
-			Language:       "en",
-			CountryNo:      "US",
+		if concurrency < 1 {
+			return fmt.Errorf("--concurrency must be at least 1")
 		}
 
-		allEvents, err := fetchEvents(token, eventsReq)
+		handler, err := cmdutil.NewOutput(output.KindEvents, fmt.Sprintf("No events found matching %s = '%s'", searchField, searchTerm))
 		if err != nil {
-			fmt.Printf("Error fetching events: %v\n", err)
-			return
+			return err
 		}
+		defer handler.Close()
 
-		// Filter events based on search field and term
-		var filteredEvents []Event
-		for _, event := range allEvents {
-			if matchesSearch(event, searchField, searchTerm) {
-				filteredEvents = append(filteredEvents, event)
+		var downloader *download.Downloader
+		if downloadMedia {
+			downloader, err = newDownloader()
+			if err != nil {
+				return err
 			}
 		}
 
-		// Display filtered events
-		if len(filteredEvents) == 0 {
-			fmt.Printf("No events found matching %s = '%s'\n", searchField, searchTerm)
-			return
+		c, err := cmdutil.NewClient()
+		if err != nil {
+			return err
 		}
 
-		if outputFormat == "json" {
-			// Output JSON format
-			prettyJSON, err := json.MarshalIndent(filteredEvents, "", "  ")
-			if err != nil {
-				fmt.Printf("Error formatting JSON: %v\n", err)
-				return
-			}
-			fmt.Println(string(prettyJSON))
-		} else {
-			// Output table format
-			fmt.Printf("%-36s %-20s %-25s %-25s %-25s\n",
-				"Trace ID", "Timestamp", "Device Name", "Bird Name", "Bird Latin")
-			fmt.Println("--------------------------------------------------------------------------------------------------")
-			for _, event := range filteredEvents {
-				fmt.Printf("%-36s %-20s %-25s %-25s %-25s\n",
-					event.TraceID,
-					event.Timestamp,
-					event.DeviceName,
-					event.BirdName,
-					event.BirdLatin)
+		ctx, cancel := cmdutil.Context(cmd)
+		defer cancel()
+
+		c.Concurrency = concurrency
+
+		// Filter events based on search field and term
+		filteredEvents, err := writeEvents(ctx, c, handler, start, end, func(event models.Event) bool {
+			return matchesSearch(event, searchField, searchTerm)
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching events: %w", err)
+		}
+
+		// Download media after printing, reporting progress on stderr so that
+		// stdout stays parseable
+		if downloader != nil {
+			if err := downloadEvents(ctx, downloader, filteredEvents, os.Stderr); err != nil {
+				return fmt.Errorf("error downloading media: %w", err)
 			}
 		}
+
+		return nil
 	},
 }
 
@@ -115,11 +106,14 @@ func init() {
 	defaultStart := currentTime.Add(-24 * time.Hour).Format("2006-01-02 15:04:05")
 	defaultEnd := currentTime.Format("2006-01-02 15:04:05")
 
-	searchCmd.Flags().StringVar(&searchField, "field", "", "Field to search (serialNumber, deviceName, birdName)")
+	searchCmd.Flags().StringVar(&searchField, "field", "", "Field to search (serialNumber, deviceName, birdName, category)")
 	searchCmd.Flags().StringVar(&searchTerm, "value", "", "Value to search for")
 	searchCmd.Flags().StringVar(&searchStartTime, "startTime", defaultStart, "Start time (format: 2006-01-02 15:04:05)")
 	searchCmd.Flags().StringVar(&searchEndTime, "endTime", defaultEnd, "End time (format: 2006-01-02 15:04:05)")
-	searchCmd.Flags().StringVar(&outputFormat, "format", "table", "Output format (table or json)")
+	cmdutil.AddListOutputFlags(searchCmd)
+	searchCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of day-sized time windows to fetch in parallel")
+	searchCmd.Flags().BoolVar(&downloadMedia, "download", false, "Also download the media of the listed events")
+	addDownloadFlags(searchCmd)
 
 	// Mark the field flag as required
 	searchCmd.MarkFlagRequired("field")
@@ -128,16 +122,17 @@ func init() {
 // matchesSearch checks if an event matches the search criteria provided by the user.
 // It compares the specified field in the event with the search term, using case-insensitive
 // matching. For some fields like deviceName and birdName, it uses substring matching,
-// while for serialNumber it requires an exact match.
+// while for serialNumber it requires an exact match. birdName matches the name of any
+// subject detected in the event, not only the top bird.
 //
 // Parameters:
 //   - event: The Event to check
-//   - field: The field name to check against (serialNumber, deviceName, birdName)
+//   - field: The field name to check against (serialNumber, deviceName, birdName, category)
 //   - term: The value to search for
 //
 // Returns:
 //   - true if the event matches the search criteria, false otherwise
-func matchesSearch(event Event, field, term string) bool {
+func matchesSearch(event models.Event, field, term string) bool {
 	term = strings.ToLower(term)
 
 	switch strings.ToLower(field) {
@@ -145,8 +140,19 @@ func matchesSearch(event Event, field, term string) bool {
 		return strings.ToLower(event.SerialNumber) == term
 	case "devicename":
 		return strings.Contains(strings.ToLower(event.DeviceName), term)
+	case "category":
+		return event.HasCategory(term)
 	case "birdname":
-		return strings.Contains(strings.ToLower(event.BirdName), term)
+		if strings.Contains(strings.ToLower(event.BirdName), term) {
+			return true
+		}
+		// Also match any other subject detected in the event
+		for _, detection := range event.Detections {
+			if strings.Contains(strings.ToLower(detection.Name), term) {
+				return true
+			}
+		}
+		return false
 	default:
 		// If the field isn't recognized, return false
 		return false
@@ -3,10 +3,29 @@ package events
 import (
 	"fmt"
 	"time"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/spf13/cobra"
 )
 
-// parseTimeParameters validates and parses the start and end time parameters
-func parseTimeParameters(startTime, endTime string) (time.Time, time.Time, error) {
+// timeRange returns the time range selected by the startTime and endTime flags of cmd.
+// Times are read in the configured time zone. A flag that was not given defaults to
+// the last 24 hours, computed when the command runs rather than at startup.
+func timeRange(cmd *cobra.Command, startTime, endTime string) (time.Time, time.Time, error) {
+	loc := cmdutil.Location()
+	now := time.Now().In(loc)
+	if !cmd.Flags().Changed("startTime") {
+		startTime = now.Add(-24 * time.Hour).Format("2006-01-02 15:04:05")
+	}
+	if !cmd.Flags().Changed("endTime") {
+		endTime = now.Format("2006-01-02 15:04:05")
+	}
+	return parseTimeParameters(startTime, endTime, loc)
+}
+
+// parseTimeParameters validates and parses the start and end time parameters.
+// Times without a zone offset are read in loc.
+func parseTimeParameters(startTime, endTime string, loc *time.Location) (time.Time, time.Time, error) {
 	// List of supported time formats
 	formats := []string{
 		"2006-01-02 15:04:05", // Standard format
@@ -20,7 +39,7 @@ func parseTimeParameters(startTime, endTime string) (time.Time, time.Time, error
 
 	// Try to parse start time with different formats
 	for _, format := range formats {
-		start, err = time.Parse(format, startTime)
+		start, err = time.ParseInLocation(format, startTime, loc)
 		if err == nil {
 			startParsed = true
 			break
@@ -33,7 +52,7 @@ func parseTimeParameters(startTime, endTime string) (time.Time, time.Time, error
 
 	// Try to parse end time with different formats
 	for _, format := range formats {
-		end, err = time.Parse(format, endTime)
+		end, err = time.ParseInLocation(format, endTime, loc)
 		if err == nil {
 			endParsed = true
 			break
@@ -0,0 +1,103 @@
+// Package mock implements the mock-server command, which runs a fake Vicohome API.
+//
+// The fake API is provided by pkg/mockserver and serves seeded fixture data, so the
+// rest of the CLI can be exercised end-to-end without network access or credentials.
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/dydx/vico-cli/pkg/mockserver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addr          string
+	email         string
+	password      string
+	tokenTTL      time.Duration
+	failAuthCode  int
+	failAuthCount int
+	failHTTPCode  int
+	failHTTPCount int
+)
+
+// mockServerCmd represents the command that runs the fake Vicohome API.
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a fake Vicohome API for offline testing",
+	Long: `Run a local fake of the Vicohome API serving seeded devices and events.
+
+Point other commands at it with --api-url and the mock credentials, for example:
+
+  vico-cli mock-server --addr 127.0.0.1:8080 &
+  VICOHOME_EMAIL=demo@example.com VICOHOME_PASSWORD=demo \
+    vico-cli events list --api-url http://127.0.0.1:8080
+
+Use --token-ttl or --fail-auth-code to simulate the API's authentication errors
+(-1024 kicked offline, -1025 token missing, -1026 token invalid, -1027 token expired),
+and --fail-http-count to simulate throttling or server errors that are retried.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server := mockserver.New()
+		server.Email = email
+		server.Password = password
+		server.TokenTTL = tokenTTL
+		if failAuthCount > 0 {
+			server.FailAuth(failAuthCode, failAuthCount)
+		}
+		if failHTTPCount > 0 {
+			server.FailHTTP(failHTTPCode, failHTTPCount)
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("error starting mock server: %w", err)
+		}
+
+		fmt.Printf("Mock Vicohome API listening on http://%s\n", listener.Addr())
+		fmt.Printf("Credentials: VICOHOME_EMAIL=%s VICOHOME_PASSWORD=%s\n", email, password)
+
+		// Serve until interrupted or --timeout expires, then let requests in flight finish
+		ctx, cancel := cmdutil.Context(cmd)
+		defer cancel()
+		httpServer := &http.Server{Handler: server}
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+
+		if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("error running mock server: %w", err)
+		}
+		<-stopped
+		fmt.Println("Mock Vicohome API stopped")
+		return nil
+	},
+}
+
+func init() {
+	mockServerCmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	mockServerCmd.Flags().StringVar(&email, "email", mockserver.DefaultEmail, "Email accepted by the login endpoint")
+	mockServerCmd.Flags().StringVar(&password, "password", mockserver.DefaultPassword, "Password accepted by the login endpoint")
+	mockServerCmd.Flags().DurationVar(&tokenTTL, "token-ttl", 0, "Lifetime of issued tokens, after which requests fail with -1027 (0 for no expiry)")
+	mockServerCmd.Flags().IntVar(&failAuthCode, "fail-auth-code", -1026, "Auth error code returned by the first --fail-auth-count requests")
+	mockServerCmd.Flags().IntVar(&failAuthCount, "fail-auth-count", 0, "Number of authenticated requests to fail with --fail-auth-code")
+	mockServerCmd.Flags().IntVar(&failHTTPCode, "fail-http-status", http.StatusServiceUnavailable, "HTTP status returned by the first --fail-http-count requests")
+	mockServerCmd.Flags().IntVar(&failHTTPCount, "fail-http-count", 0, "Number of requests to fail with --fail-http-status")
+}
+
+// GetMockServerCmd returns the mock-server command.
+// This function is called by the root command to add the fake API server to the CLI.
+func GetMockServerCmd() *cobra.Command {
+	return mockServerCmd
+}
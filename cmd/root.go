@@ -6,11 +6,22 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/dydx/vico-cli/cmd/auth"
+	"github.com/dydx/vico-cli/cmd/cmdutil"
+	"github.com/dydx/vico-cli/cmd/config"
 	"github.com/dydx/vico-cli/cmd/devices"
 	"github.com/dydx/vico-cli/cmd/events"
+	"github.com/dydx/vico-cli/cmd/mock"
+	"github.com/dydx/vico-cli/pkg/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -18,22 +29,72 @@ import (
 // It represents the current version of the CLI application.
 var Version = "dev"
 
-var cfgFile string
-
 var rootCmd = &cobra.Command{
 	Use:   "vico-cli",
 	Short: "Interact with Vicohome API",
-	Long:  `A CLI tool for interacting with the Vicohome API to fetch and manage events.`,
+	Long: `A CLI tool for interacting with the Vicohome API to fetch and manage events.
+
+Exit codes:
+    0  success
+    1  other errors, such as invalid flags, arguments or configuration
+    2  authentication failed
+    3  device or event not found
+    4  the API returned an error
+    5  network error or timeout
+  130  interrupted by SIGINT or SIGTERM`,
+	// Errors are printed by Execute, which also selects the exit code
+	SilenceErrors: true,
+	// Apply the configuration profile before any command runs
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Errors from here on are not caused by command line usage
+		cmd.SilenceUsage = true
+		if err := cmdutil.SetupLogging(); err != nil {
+			return err
+		}
+		logging.Logger().DebugContext(cmd.Context(), "running command", "command", cmd.CommandPath())
+		return cmdutil.ApplyConfig(cmd)
+	},
 }
 
 // Execute runs the root command and handles any resulting errors.
-// If the command execution fails, the program will exit with a non-zero status code.
+// If the command execution fails, the error is printed to stderr and the program exits
+// with the status code for the kind of error, see cmdutil.ExitCode.
 // This function is called by the main function and serves as the entry point for the CLI.
+//
+// The first SIGINT or SIGTERM cancels the commands' context, so that requests and
+// downloads in progress stop, partial files are kept for resuming and output is
+// completed. A second signal terminates the program immediately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// Restore the default signal handling once the context is canceled
+		<-ctx.Done()
+		stop()
+	}()
+
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	logging.Logger().Debug("command finished", "command", cmd.CommandPath(),
+		"exit_code", cmdutil.ExitCode(err), "duration_ms", time.Since(start).Milliseconds())
 	if err != nil {
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, errorMessage(err))
+		os.Exit(cmdutil.ExitCode(err))
+	}
+}
+
+// errorMessage formats an error for display. Errors that name the failed operation,
+// such as "error fetching devices: ...", are capitalized; others are prefixed with "Error: ".
+// Interruptions are reported without the details of the canceled request.
+func errorMessage(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return "Interrupted"
+	}
+	msg := err.Error()
+	if strings.HasPrefix(msg, "error ") {
+		return "E" + msg[1:]
 	}
+	return "Error: " + msg
 }
 
 // versionCmd represents the version command, which displays the current version of the CLI.
@@ -47,10 +108,13 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
-	// No persistent flags needed
+	cmdutil.AddGlobalFlags(rootCmd)
 
 	// Add the commands
+	rootCmd.AddCommand(auth.GetAuthCmd())
+	rootCmd.AddCommand(config.GetConfigCmd())
 	rootCmd.AddCommand(devices.GetDevicesCmd())
 	rootCmd.AddCommand(events.GetEventsCmd())
+	rootCmd.AddCommand(mock.GetMockServerCmd())
 	rootCmd.AddCommand(versionCmd)
 }
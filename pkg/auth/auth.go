@@ -7,14 +7,21 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dydx/vico-cli/pkg/cache"
+	"github.com/dydx/vico-cli/pkg/logging"
+	"github.com/dydx/vico-cli/pkg/retry"
 )
 
 // Error codes from the API
@@ -25,19 +32,6 @@ const (
 	ErrorTokenExpired  = -1027 // Token has expired
 )
 
-// isDebugMode returns true if debug logging is enabled
-func isDebugMode() bool {
-	debug := os.Getenv("VICOHOME_DEBUG")
-	return debug == "true" || debug == "1"
-}
-
-// logDebug prints a message only if debug mode is enabled
-func logDebug(format string, args ...interface{}) {
-	if isDebugMode() {
-		fmt.Fprintf(os.Stderr, format, args...)
-	}
-}
-
 // LoginRequest represents the JSON request body sent to the Vicohome API
 // during authentication.
 type LoginRequest struct {
@@ -58,63 +52,277 @@ type LoginResponse struct {
 	} `json:"data"`
 }
 
+// DefaultBaseURL is the API endpoint used for login when no other base URL is configured.
+const DefaultBaseURL = "https://api-us.vicohome.io"
+
+// RequestTimeout bounds each login request, so that a hung connection cannot block
+// authentication forever.
+const RequestTimeout = 30 * time.Second
+
 // Authenticate obtains an authentication token for the Vicohome API.
 // It first tries to retrieve a valid cached token. If no valid token is found,
 // it falls back to direct authentication using credentials from environment variables.
 // Successfully acquired tokens are cached for future use to minimize authentication requests.
+// Login requests are sent to DefaultBaseURL; use a TokenSource to target another endpoint.
 //
 // Returns:
 //   - string: The authentication token if successful
 //   - error: Any error encountered during the authentication process
 func Authenticate() (string, error) {
+	return NewTokenSource(DefaultBaseURL).Token(context.Background())
+}
+
+// RefreshToken discards any cached token and authenticates again against
+// DefaultBaseURL using the credentials from the environment. The new token is
+// written back to the cache.
+//
+// Returns:
+//   - string: The new authentication token if successful
+//   - error: Any error encountered during the authentication process
+func RefreshToken() (string, error) {
+	return NewTokenSource(DefaultBaseURL).Refresh(context.Background())
+}
+
+// TokenSource provides tokens from the on-disk cache, authenticating with its
+// credentials when no valid cached token exists. If Email or Password is empty,
+// the VICOHOME_EMAIL and VICOHOME_PASSWORD environment variables are used instead.
+// Tokens are refreshed shortly before they expire, see RefreshMargin.
+// Logins are coordinated through the cache lock, so that when several processes
+// need a new token at once, only one logs in and the others use its token.
+// It satisfies the client.TokenSource interface.
+type TokenSource struct {
+	BaseURL    string       // API base URL that login requests are sent to
+	Email      string       // Account email address
+	Password   string       // Account password
+	Retry      retry.Policy // How login requests that fail for transient reasons are retried
+	HTTPClient *http.Client // HTTP client used for login requests; nil means one with RequestTimeout
+
+	// The last token returned and when it should be refreshed
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+// NewTokenSource creates a token source backed by the token cache that logs in
+// against the given API base URL. An empty base URL selects DefaultBaseURL.
+func NewTokenSource(baseURL string) *TokenSource {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &TokenSource{BaseURL: strings.TrimRight(baseURL, "/"), Retry: retry.DefaultPolicy()}
+}
+
+// Token returns a cached token or authenticates to obtain a new one.
+// The cached token of the source's account and base URL is used; without an
+// email address, the token of the account that last logged in to the base URL is.
+// A cached token that is about to expire is refreshed, but still returned if
+// logging in fails. The login request is canceled when ctx is done.
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	email, password := s.credentials()
+
 	// Try to get a cached token first
-	cacheManager, err := cache.NewTokenCacheManager()
+	cacheManager, err := cache.NewAccountTokenCacheManager(email, s.BaseURL)
 	if err != nil {
 		// If we can't create a cache manager, fall back to direct authentication
-		logDebug("Warning: Could not create token cache manager: %v\n", err)
-		return authenticateDirectly()
+		logging.Logger().WarnContext(ctx, "could not open token cache, logging in without it", logging.Error(err))
+		return s.login(ctx, nil, email, password)
 	}
+	cacheManager.LockTimeout = s.loginTimeout()
 
-	token, valid := cacheManager.GetToken()
-	if valid {
-		logDebug("Using cached token\n")
-		// We have a valid cached token, return it
-		return token, nil
+	if cached, ok := s.cachedToken(cacheManager); ok {
+		logging.Logger().DebugContext(ctx, "using cached token", "expires_in", time.Until(cached.ExpiresAt).Round(time.Second).String())
+		return cached.Token, nil
+	}
+	logging.Logger().DebugContext(ctx, "no cached token or it is due for refresh, logging in", "base_url", s.BaseURL)
+
+	// Log in while holding the cache lock, unless another process has logged in
+	// while this one waited for the lock
+	var token string
+	locked := false
+	err = cacheManager.WithLock(ctx, func() error {
+		locked = true
+		if cached, ok := s.cachedToken(cacheManager); ok {
+			logging.Logger().DebugContext(ctx, "using token obtained by another process", "base_url", s.BaseURL)
+			token = cached.Token
+			return nil
+		}
+
+		var err error
+		token, err = s.login(ctx, cacheManager, email, password)
+		return err
+	})
+	if err != nil && !locked && ctx.Err() == nil && !errors.Is(err, cache.ErrLockTimeout) {
+		// The lock file cannot be used at all, for example on a read-only file system.
+		// A lock held for too long is an error, so that waiting processes do not all
+		// log in at once
+		logging.Logger().WarnContext(ctx, "could not lock token cache, logging in without the lock", logging.Error(err))
+		token, err = s.login(ctx, cacheManager, email, password)
+	}
+	if err != nil {
+		if cached, ok := cacheManager.Load(); ok && time.Until(cached.ExpiresAt) > 0 {
+			// The cached token has not expired yet, keep using it
+			logging.Logger().WarnContext(ctx, "proactive token refresh failed, using cached token",
+				"expires_in", time.Until(cached.ExpiresAt).Round(time.Second).String(), logging.Error(err))
+			// Try again in a minute rather than on every request
+			s.mu.Lock()
+			s.token = cached.Token
+			s.refreshAt = time.Now().Add(time.Minute)
+			s.mu.Unlock()
+			return cached.Token, nil
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+// Refresh replaces the token last returned, which the API no longer accepts, by
+// authenticating again. If another process has already cached a different token for
+// the account in the meantime, that token is returned instead of logging in again.
+// The login request is canceled when ctx is done.
+func (s *TokenSource) Refresh(ctx context.Context) (string, error) {
+	email, password := s.credentials()
+
+	cacheManager, err := cache.NewAccountTokenCacheManager(email, s.BaseURL)
+	if err != nil {
+		logging.Logger().WarnContext(ctx, "could not open token cache, logging in without it", logging.Error(err))
+		return s.login(ctx, nil, email, password)
+	}
+	cacheManager.LockTimeout = s.loginTimeout()
+
+	s.mu.Lock()
+	rejected := s.token
+	s.mu.Unlock()
+
+	var token string
+	locked := false
+	err = cacheManager.WithLock(ctx, func() error {
+		locked = true
+		cached, ok := cacheManager.Load()
+		if ok && rejected != "" && cached.Token != rejected && time.Until(cached.ExpiresAt) > 0 {
+			logging.Logger().DebugContext(ctx, "using token refreshed by another process", "base_url", s.BaseURL)
+			s.setToken(cached.Token, cached.IssuedAt, cached.ExpiresAt)
+			token = cached.Token
+			return nil
+		}
+
+		// Get a new token directly (bypass cache). The rejected token stays cached
+		// until it is replaced, or discarded if logging in fails
+		var err error
+		token, err = s.login(ctx, cacheManager, email, password)
+		if err != nil {
+			cacheManager.ClearToken()
+		}
+		return err
+	})
+	if err != nil && !locked && ctx.Err() == nil && !errors.Is(err, cache.ErrLockTimeout) {
+		logging.Logger().WarnContext(ctx, "could not lock token cache, logging in without the lock", logging.Error(err))
+		return s.login(ctx, cacheManager, email, password)
+	}
+	return token, err
+}
+
+// loginTimeout returns the longest time a login can take with the source's retry
+// policy: every attempt running into the request timeout, with the longest allowed
+// delay between attempts. Other processes wait this long for the cache lock while
+// this one logs in, rather than giving up and logging in themselves.
+func (s *TokenSource) loginTimeout() time.Duration {
+	requestTimeout := RequestTimeout
+	if s.HTTPClient != nil && s.HTTPClient.Timeout > 0 {
+		requestTimeout = s.HTTPClient.Timeout
+	}
+	attempts := s.Retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := s.Retry.MaxBackoff
+	if s.Retry.MaxRetryAfter > delay {
+		delay = s.Retry.MaxRetryAfter
+	}
+	return time.Duration(attempts)*requestTimeout + time.Duration(attempts-1)*delay
+}
+
+// RefreshAt returns when the token last returned by Token or Refresh should be
+// refreshed, or the zero time if no token has been returned yet. Clients that hold
+// on to a token call Token again after this time to obtain a fresh one.
+func (s *TokenSource) RefreshAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshAt
+}
+
+// cachedToken returns the account's cached token if it is not yet due for refresh.
+func (s *TokenSource) cachedToken(cacheManager *cache.TokenCacheManager) (cache.TokenCache, bool) {
+	cached, ok := cacheManager.Load()
+	if !ok || time.Until(cached.ExpiresAt) <= 0 {
+		// No valid cached token, authenticate and cache the new token
+		return cache.TokenCache{}, false
+	}
+	if time.Now().After(refreshAt(cached.IssuedAt, cached.ExpiresAt)) {
+		return cache.TokenCache{}, false
 	}
 
-	// No valid cached token, authenticate and cache the new token
-	logDebug("No valid cached token found, authenticating directly\n")
-	token, err = authenticateDirectly()
+	s.setToken(cached.Token, cached.IssuedAt, cached.ExpiresAt)
+	return cached, true
+}
+
+// login authenticates with the given credentials and caches the new token until
+// its expiry. A nil cacheManager skips caching.
+func (s *TokenSource) login(ctx context.Context, cacheManager *cache.TokenCacheManager, email, password string) (string, error) {
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: RequestTimeout}
+	}
+	token, expiresAt, err := authenticateDirectly(ctx, httpClient, s.BaseURL, email, password, s.Retry)
 	if err != nil {
 		return "", err
 	}
+	s.setToken(token, time.Now(), expiresAt)
 
-	// Cache the token for future use (24 hours validity)
-	if err := cacheManager.SaveToken(token, 24); err != nil {
+	if cacheManager == nil {
+		return token, nil
+	}
+	if err := cacheManager.SaveAccountTokenUntil(email, token, expiresAt); err != nil {
 		// Non-fatal error, we can still return the token
-		logDebug("Warning: failed to cache token: %v\n", err)
-	} else {
-		logDebug("Successfully cached new token\n")
+		logging.Logger().WarnContext(ctx, "could not cache token", logging.Error(err))
 	}
 
 	return token, nil
 }
 
+// setToken records the token being returned and its lifetime.
+func (s *TokenSource) setToken(token string, issuedAt, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	s.refreshAt = refreshAt(issuedAt, expiresAt)
+}
+
+// credentials returns the source's email and password, falling back to the
+// VICOHOME_EMAIL and VICOHOME_PASSWORD environment variables for missing values.
+func (s *TokenSource) credentials() (string, string) {
+	email, password := s.Email, s.Password
+	if email == "" {
+		email = os.Getenv("VICOHOME_EMAIL")
+	}
+	if password == "" {
+		password = os.Getenv("VICOHOME_PASSWORD")
+	}
+	return email, password
+}
+
 // authenticateDirectly performs authentication to the Vicohome API without using the token cache.
-// It retrieves credentials from environment variables (VICOHOME_EMAIL and VICOHOME_PASSWORD),
-// makes an authentication request to the API, and parses the response to extract the token.
+// It sends an authentication request with the given credentials to the API at baseURL
+// through client, retried according to policy, and parses the response to extract the
+// token and its expiry.
 //
 // Returns:
 //   - string: The authentication token if successful
+//   - time.Time: When the token expires, see tokenExpiry
 //   - error: Any error encountered during the authentication process
-func authenticateDirectly() (string, error) {
-	// Get credentials from environment variables
-	email := os.Getenv("VICOHOME_EMAIL")
-	password := os.Getenv("VICOHOME_PASSWORD")
-
+func authenticateDirectly(ctx context.Context, client *http.Client, baseURL, email, password string, policy retry.Policy) (string, time.Time, error) {
 	// Check if credentials are available
 	if email == "" || password == "" {
-		return "", fmt.Errorf("Error: VICOHOME_EMAIL and VICOHOME_PASSWORD environment variables are required")
+		return "", time.Time{}, &AuthError{Msg: "VICOHOME_EMAIL and VICOHOME_PASSWORD environment variables are required (or log in with 'vico-cli auth login')"}
 	}
 	// Use the proper JSON marshaling to avoid escaping issues
 	loginReq := map[string]interface{}{
@@ -125,58 +333,75 @@ func authenticateDirectly() (string, error) {
 
 	reqBody, err := json.Marshal(loginReq)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling login request: %w", err)
+		return "", time.Time{}, fmt.Errorf("error marshaling login request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api-us.vicohome.io/account/login", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/account/login", bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("error creating request: %w", err)
+		return "", time.Time{}, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	start := time.Now()
+	attempts := 0
+	resp, err := policy.Do(ctx, func() (*http.Response, error) {
+		attempts++
+		req.Body, _ = req.GetBody()
+		return client.Do(req)
+	})
 	if err != nil {
-		return "", fmt.Errorf("error making request: %w", err)
+		logging.LogRequest(ctx, req.Method, req.URL.String(), 0, nil, attempts, time.Since(start), err)
+		return "", time.Time{}, &NetworkError{Op: "making request", Err: err}
 	}
 	defer resp.Body.Close()
 
+	if policy.Retryable(resp.StatusCode) {
+		logging.LogRequest(ctx, req.Method, req.URL.String(), resp.StatusCode, nil, attempts, time.Since(start), nil)
+		return "", time.Time{}, &APIError{Msg: http.StatusText(resp.StatusCode), HTTPStatus: resp.StatusCode}
+	}
+
 	respBody, err := io.ReadAll(resp.Body)
+	logging.LogRequest(ctx, req.Method, req.URL.String(), resp.StatusCode, respBody, attempts, time.Since(start), err)
 	if err != nil {
-		return "", fmt.Errorf("error reading response body: %w", err)
+		return "", time.Time{}, &NetworkError{Op: "reading response body", Err: err}
 	}
 
 	// Try to parse as generic map first to handle all possible response formats
 	var responseMap map[string]interface{}
 	if err := json.Unmarshal(respBody, &responseMap); err != nil {
-		return "", fmt.Errorf("error unmarshaling response: %w\nResponse: %s", err, string(respBody))
+		return "", time.Time{}, fmt.Errorf("error unmarshaling response: %w\nResponse: %s", err, string(respBody))
 	}
 
-	// Check if there's a result code and error message in the API response
+	// Check if there's a result code and error message in the API response.
+	// A failed login means the credentials were rejected.
 	if result, ok := responseMap["result"].(float64); ok && result != 0 {
 		msg, _ := responseMap["msg"].(string)
-		return "", fmt.Errorf("API error: %s (code: %.0f)", msg, result)
+		return "", time.Time{}, &AuthError{Code: int(result), Msg: msg}
 	}
 
 	// Check if we have data.token.token in the response
 	data, ok := responseMap["data"].(map[string]interface{})
 	if !ok || len(data) == 0 {
-		return "", fmt.Errorf("login failed: missing data in response")
+		return "", time.Time{}, fmt.Errorf("login failed: missing data in response")
 	}
 
 	tokenObj, ok := data["token"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("login failed: missing token in response")
+		return "", time.Time{}, fmt.Errorf("login failed: missing token in response")
 	}
 
 	tokenStr, ok := tokenObj["token"].(string)
 	if !ok || tokenStr == "" {
-		return "", fmt.Errorf("login failed: empty token in response")
+		return "", time.Time{}, fmt.Errorf("login failed: empty token in response")
 	}
 
-	return tokenStr, nil
+	expiresAt, from := tokenExpiry(tokenObj, tokenStr, time.Now())
+	logging.Logger().DebugContext(ctx, "logged in", "expires_at", expiresAt.Format(time.RFC3339),
+		"expires_in", time.Until(expiresAt).Round(time.Second).String(), "expiry_source", from)
+
+	return tokenStr, expiresAt, nil
 }
 
 // ValidateResponse checks if an API response contains an authentication error
@@ -189,19 +414,20 @@ func authenticateDirectly() (string, error) {
 //
 // Returns:
 //   - bool: True if the token needs to be refreshed, false otherwise
-//   - error: Any error found in the response, or nil if no error was found
+//   - error: Any error found in the response, or nil if no error was found. Errors
+//     reported by the API are an *AuthError or an *APIError
 func ValidateResponse(respBody []byte) (bool, error) {
 	// Check if we have a non-JSON response (probably HTML error page)
 	if len(respBody) > 0 && (respBody[0] == '<' || respBody[0] == '\r' || respBody[0] == '\n') {
-		// Print a preview for debugging
-		if isDebugMode() {
+		// Log a preview for debugging
+		if logging.Logger().Enabled(context.Background(), slog.LevelDebug) {
 			preview := string(respBody)
 			if len(preview) > 100 {
 				preview = preview[:100] + "..."
 			}
-			logDebug("Warning: Received non-JSON response (likely auth issue): %s\n", preview)
+			logging.Logger().Debug("received non-JSON response", "preview", preview)
 		}
-		return true, fmt.Errorf("received non-JSON response (likely authentication issue)")
+		return true, &AuthError{Msg: "received non-JSON response (likely authentication issue)"}
 	}
 
 	// Try to parse the response
@@ -210,12 +436,6 @@ func ValidateResponse(respBody []byte) (bool, error) {
 		return false, fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
-	// Print the response for debugging
-	if isDebugMode() {
-		prettyJSON, _ := json.MarshalIndent(responseMap, "", "  ")
-		logDebug("API Response: %s\n", string(prettyJSON))
-	}
-
 	// Check for authentication errors - try both result and code fields (API inconsistency)
 	var errorCode float64
 	var errorMsg string
@@ -256,15 +476,13 @@ func ValidateResponse(respBody []byte) (bool, error) {
 		}
 
 		if isAuthError {
-			if isDebugMode() {
-				logDebug("Auth error detected: %s (code: %.0f)\n", errorMsg, errorCode)
-			}
+			logging.Logger().Debug("API rejected the token", "code", int(errorCode), "msg", errorMsg)
 			// Don't clear cache here, let the caller handle it
-			return true, fmt.Errorf("authentication error: %s (code: %.0f)", errorMsg, errorCode)
+			return true, &AuthError{Code: int(errorCode), Msg: errorMsg}
 		}
 
 		// Otherwise it's a regular API error
-		return false, fmt.Errorf("API error: %s (code: %.0f)", errorMsg, errorCode)
+		return false, &APIError{Code: int(errorCode), Msg: errorMsg}
 	}
 
 	return false, nil
@@ -273,7 +491,10 @@ func ValidateResponse(respBody []byte) (bool, error) {
 // ExecuteWithRetry executes an HTTP request with automatic token refresh on authentication errors.
 // If the initial request fails due to an authentication error, it refreshes the token and
 // retries the request once with the new token. This handles cases where a token has expired
-// or been invalidated since it was cached.
+// or been invalidated since it was cached. The refresh login is sent to the same scheme and
+// host as the request itself, so requests to any region are refreshed against that region.
+// Requests that fail for transient reasons are retried according to retry.DefaultPolicy.
+// The request's context governs the retries and the refresh login as well.
 //
 // Parameters:
 //   - req: The HTTP request to execute
@@ -283,7 +504,7 @@ func ValidateResponse(respBody []byte) (bool, error) {
 //   - error: Any error encountered during the request process
 func ExecuteWithRetry(req *http.Request) ([]byte, error) {
 	// First attempt with current token
-	client := &http.Client{}
+	client := &http.Client{Timeout: RequestTimeout}
 
 	// Make sure we can reuse the request body if needed
 	var requestBodyBytes []byte
@@ -296,47 +517,52 @@ func ExecuteWithRetry(req *http.Request) ([]byte, error) {
 		req.Body = io.NopCloser(bytes.NewBuffer(requestBodyBytes))
 	}
 
+	// send executes a request, retrying transient failures with a fresh copy of the body
+	policy := retry.DefaultPolicy()
+	send := func(req *http.Request, op string) (*http.Response, error) {
+		resp, err := policy.Do(req.Context(), func() (*http.Response, error) {
+			if requestBodyBytes != nil {
+				req.Body = io.NopCloser(bytes.NewReader(requestBodyBytes))
+			}
+			return client.Do(req)
+		})
+		if err != nil {
+			return nil, &NetworkError{Op: op, Err: err}
+		}
+		if policy.Retryable(resp.StatusCode) {
+			resp.Body.Close()
+			return nil, &APIError{Msg: http.StatusText(resp.StatusCode), HTTPStatus: resp.StatusCode}
+		}
+		return resp, nil
+	}
+
 	// First attempt
-	resp, err := client.Do(req)
+	resp, err := send(req, "making request")
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, &NetworkError{Op: "reading response body", Err: err}
 	}
 
 	// Check if we need to refresh the token
 	needsRefresh, apiErr := ValidateResponse(respBody)
 	if needsRefresh {
-		// Only show detailed logs in debug mode
-		logDebug("Token refresh needed: %v\n", apiErr)
-
-		// Clear the cache and get a new token
-		cacheManager, err := cache.NewTokenCacheManager()
-		if err == nil {
-			cacheManager.ClearToken()
-		}
+		logging.Logger().DebugContext(req.Context(), "refreshing rejected token", logging.Error(apiErr))
 
-		// Get a new token directly (bypass cache)
-		token, err := authenticateDirectly()
+		// Clear the cache and log in again against the same API host as the request
+		source := NewTokenSource(req.URL.Scheme + "://" + req.URL.Host)
+		source.token = req.Header.Get("Authorization")
+		token, err := source.Refresh(req.Context())
 		if err != nil {
 			return nil, fmt.Errorf("failed to refresh token: %w", err)
 		}
 
-		// Cache the new token with verbose error handling
-		if cacheManager != nil {
-			if err := cacheManager.SaveToken(token, 24); err != nil {
-				logDebug("Warning: failed to cache refreshed token: %v\n", err)
-			} else {
-				logDebug("Successfully refreshed and cached new token\n")
-			}
-		}
-
 		// Create a new request with the same parameters but new token
-		newReq, err := http.NewRequest(req.Method, req.URL.String(), nil)
+		newReq, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), nil)
 		if err != nil {
 			return nil, fmt.Errorf("error creating request for retry: %w", err)
 		}
@@ -358,23 +584,26 @@ func ExecuteWithRetry(req *http.Request) ([]byte, error) {
 		}
 
 		// Retry the request with the new token
-		logDebug("Retrying request with refreshed token\n")
-		resp, err = client.Do(newReq)
+		logging.Logger().DebugContext(req.Context(), "retrying request with refreshed token")
+		resp, err = send(newReq, "making request after token refresh")
 		if err != nil {
-			return nil, fmt.Errorf("error making request after token refresh: %w", err)
+			return nil, err
 		}
 		defer resp.Body.Close()
 
 		respBody, err = io.ReadAll(resp.Body)
 		if err != nil {
-			return nil, fmt.Errorf("error reading response body after token refresh: %w", err)
+			return nil, &NetworkError{Op: "reading response body after token refresh", Err: err}
 		}
 
 		// Check if we still got an error after refreshing the token
 		needsRefresh2, apiError := ValidateResponse(respBody)
-		if needsRefresh2 || apiError != nil {
+		if needsRefresh2 {
 			// This is a critical error worth showing - authentication failed even after token refresh
-			return nil, fmt.Errorf("authentication failed even after token refresh: %v", apiError)
+			return nil, &AuthError{Msg: "token not accepted even after refresh", Err: apiError}
+		}
+		if apiError != nil {
+			return nil, apiError
 		}
 	}
 
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"fmt"
+)
+
+// AuthError reports that authentication failed: the credentials are missing or were
+// rejected, or the API did not accept the token even after it was refreshed.
+type AuthError struct {
+	Code int    // The API's result code, or 0 if the failure was detected locally
+	Msg  string // Description of the failure
+	Err  error  // The underlying error, if any
+}
+
+// Error returns the failure description, with the API's result code if there is one.
+func (e *AuthError) Error() string {
+	msg := e.Msg
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	if e.Code == 0 {
+		return "authentication failed: " + msg
+	}
+	return fmt.Sprintf("authentication error: %s (code: %d)", msg, e.Code)
+}
+
+// Unwrap returns the underlying error.
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// APIError reports an error result returned by the API for a request that was
+// otherwise authenticated. Code holds the response's result or code field. Requests
+// that still fail with a retryable HTTP status after all retries are reported with
+// the status in HTTPStatus instead.
+type APIError struct {
+	Code       int    // The API's result code
+	Msg        string // The API's message
+	HTTPStatus int    // The HTTP status code, if the API answered with an error status
+}
+
+// Error returns the API's message and result code, or the HTTP status.
+func (e *APIError) Error() string {
+	if e.HTTPStatus != 0 {
+		return fmt.Sprintf("API error: %s (HTTP %d)", e.Msg, e.HTTPStatus)
+	}
+	return fmt.Sprintf("API error: %s (code: %d)", e.Msg, e.Code)
+}
+
+// NetworkError reports that a request could not be sent or its response could not
+// be read, for example because the API is unreachable.
+type NetworkError struct {
+	Op  string // What failed, e.g. "making request"
+	Err error  // The underlying error
+}
+
+// Error returns the failed operation and its cause.
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("error %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError reports that the API returned no data for a requested item.
+type NotFoundError struct {
+	Kind string // The kind of item, e.g. "device"
+	ID   string // The requested identifier
+}
+
+// Error names the item that was not found.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Kind, e.ID)
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTokenLifetime is assumed for tokens whose expiry is neither reported by the
+	// login response nor contained in the token itself.
+	DefaultTokenLifetime = 24 * time.Hour
+
+	// RefreshMargin is how long before its expiry a token is refreshed proactively.
+	// Tokens with a short lifetime are refreshed when a quarter of it remains instead.
+	RefreshMargin = 5 * time.Minute
+)
+
+// Fields of the login response's token object that may hold the token's expiry.
+var (
+	// expiryFields hold an absolute time, as Unix seconds, Unix milliseconds or RFC 3339
+	expiryFields = []string{"expireTime", "expiresAt", "expireAt", "expiredTime", "expiration"}
+	// lifetimeFields hold the remaining lifetime in seconds
+	lifetimeFields = []string{"expiresIn", "expireIn", "expires_in"}
+)
+
+// tokenExpiry determines when a token returned by a login request expires. The expiry
+// reported in the response's token object is used if present, then the exp claim of
+// the token if it is a JWT. Otherwise DefaultTokenLifetime is assumed.
+//
+// Parameters:
+//   - tokenObj: The token object of the login response (data.token)
+//   - token: The token itself
+//   - now: The time the token was received
+//
+// Returns:
+//   - time.Time: When the token expires
+//   - string: Where the expiry was taken from, for debug output
+func tokenExpiry(tokenObj map[string]interface{}, token string, now time.Time) (time.Time, string) {
+	for _, field := range expiryFields {
+		if expiresAt, ok := parseTime(tokenObj[field]); ok {
+			return expiresAt, "login response field " + field
+		}
+	}
+
+	for _, field := range lifetimeFields {
+		if seconds, ok := parseNumber(tokenObj[field]); ok && seconds > 0 {
+			return now.Add(time.Duration(seconds * float64(time.Second))), "login response field " + field
+		}
+	}
+
+	if expiresAt, ok := jwtExpiry(token); ok {
+		return expiresAt, "JWT exp claim"
+	}
+
+	return now.Add(DefaultTokenLifetime), "default lifetime"
+}
+
+// refreshAt returns when a token should be refreshed proactively: RefreshMargin before
+// it expires, or when a quarter of its lifetime remains if that is shorter.
+// A zero issuedAt means the lifetime is unknown and RefreshMargin is used.
+func refreshAt(issuedAt, expiresAt time.Time) time.Time {
+	margin := RefreshMargin
+	if !issuedAt.IsZero() {
+		if quarter := expiresAt.Sub(issuedAt) / 4; quarter < margin {
+			margin = quarter
+		}
+	}
+	return expiresAt.Add(-margin)
+}
+
+// jwtExpiry returns the exp claim of a JWT, which may be prefixed with "Bearer ".
+// The signature is not verified, since the claim is only used to schedule refreshes.
+func jwtExpiry(token string) (time.Time, bool) {
+	token = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "Bearer "))
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// parseTime reads an absolute time from a JSON value holding Unix seconds, Unix
+// milliseconds or an RFC 3339 string.
+func parseTime(value interface{}) (time.Time, bool) {
+	if s, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, true
+		}
+	}
+
+	n, ok := parseNumber(value)
+	if !ok || n <= 0 {
+		return time.Time{}, false
+	}
+	// Values this large are milliseconds; seconds would be thousands of years away
+	if n > 1e11 {
+		return time.UnixMilli(int64(n)), true
+	}
+	return time.Unix(int64(n), 0), true
+}
+
+// parseNumber reads a number from a JSON value holding a number or a numeric string.
+func parseNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := json.Number(strings.TrimSpace(v)).Float64()
+		return n, err == nil
+	}
+	return 0, false
+}
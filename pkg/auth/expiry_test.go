@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// jwt returns an unsigned JWT with the given payload.
+func jwt(payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(payload)) + ".signature"
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiry := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		tokenObj   map[string]interface{}
+		token      string
+		want       time.Time
+		wantSource string
+	}{
+		{
+			name:       "Unix seconds",
+			tokenObj:   map[string]interface{}{"expireTime": float64(expiry.Unix())},
+			want:       expiry,
+			wantSource: "login response field expireTime",
+		},
+		{
+			name:       "Unix milliseconds",
+			tokenObj:   map[string]interface{}{"expiresAt": float64(expiry.UnixMilli())},
+			want:       expiry,
+			wantSource: "login response field expiresAt",
+		},
+		{
+			name:       "seconds as a string",
+			tokenObj:   map[string]interface{}{"expireAt": " 1709380800 "},
+			want:       expiry,
+			wantSource: "login response field expireAt",
+		},
+		{
+			name:       "RFC 3339",
+			tokenObj:   map[string]interface{}{"expiration": "2024-03-02T13:00:00+01:00"},
+			want:       expiry,
+			wantSource: "login response field expiration",
+		},
+		{
+			name:       "lifetime in seconds",
+			tokenObj:   map[string]interface{}{"expiresIn": float64(3600)},
+			want:       now.Add(time.Hour),
+			wantSource: "login response field expiresIn",
+		},
+		{
+			name:       "absolute time before lifetime",
+			tokenObj:   map[string]interface{}{"expires_in": float64(60), "expiredTime": float64(expiry.Unix())},
+			want:       expiry,
+			wantSource: "login response field expiredTime",
+		},
+		{
+			name:       "response field before JWT",
+			tokenObj:   map[string]interface{}{"expireIn": "7200"},
+			token:      jwt(`{"exp":1709380800}`),
+			want:       now.Add(2 * time.Hour),
+			wantSource: "login response field expireIn",
+		},
+		{
+			name:       "JWT exp claim",
+			tokenObj:   map[string]interface{}{"token": "x"},
+			token:      jwt(`{"sub":"me","exp":1709380800}`),
+			want:       expiry,
+			wantSource: "JWT exp claim",
+		},
+		{
+			name:       "JWT with Bearer prefix",
+			token:      "Bearer " + jwt(`{"exp":1709380800}`),
+			want:       expiry,
+			wantSource: "JWT exp claim",
+		},
+		{
+			name:       "unusable response fields",
+			tokenObj:   map[string]interface{}{"expireTime": "soon", "expiresAt": float64(0), "expiresIn": float64(-5)},
+			want:       now.Add(DefaultTokenLifetime),
+			wantSource: "default lifetime",
+		},
+		{
+			name:       "opaque token",
+			token:      "mock-token-1",
+			want:       now.Add(DefaultTokenLifetime),
+			wantSource: "default lifetime",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source := tokenExpiry(tt.tokenObj, tt.token, now)
+			if !got.Equal(tt.want) || source != tt.wantSource {
+				t.Errorf("tokenExpiry = %s (%s), want %s (%s)", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "exp claim", token: jwt(`{"exp":1709380800}`), want: time.Unix(1709380800, 0), wantOK: true},
+		{name: "fractional exp", token: jwt(`{"exp":1709380800.5}`), want: time.Unix(1709380800, 0), wantOK: true},
+		{name: "padded payload", token: "header." + base64.URLEncoding.EncodeToString([]byte(`{"exp":1709380800} `)) + ".signature", want: time.Unix(1709380800, 0), wantOK: true},
+		{name: "no exp claim", token: jwt(`{"sub":"me"}`)},
+		{name: "zero exp", token: jwt(`{"exp":0}`)},
+		{name: "exp not a number", token: jwt(`{"exp":"tomorrow"}`)},
+		{name: "payload not JSON", token: jwt(`not json`)},
+		{name: "payload not base64", token: "header.!!!.signature"},
+		{name: "two parts", token: "header.payload"},
+		{name: "empty", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := jwtExpiry(tt.token)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("jwtExpiry = %s, %t, want %s, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "seconds", value: float64(1709380800), want: time.Unix(1709380800, 0), wantOK: true},
+		{name: "milliseconds", value: float64(1709380800123), want: time.UnixMilli(1709380800123), wantOK: true},
+		{name: "largest seconds", value: float64(1e11), want: time.Unix(1e11, 0), wantOK: true},
+		{name: "numeric string", value: "1709380800000", want: time.UnixMilli(1709380800000), wantOK: true},
+		{name: "RFC 3339", value: "2024-03-02T12:00:00Z", want: time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "zero", value: float64(0)},
+		{name: "negative", value: float64(-1)},
+		{name: "text", value: "tomorrow"},
+		{name: "missing", value: nil},
+		{name: "boolean", value: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTime(tt.value)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("parseTime(%v) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRefreshAt(t *testing.T) {
+	issued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		lifetime time.Duration
+		want     time.Duration // before expiry
+	}{
+		{name: "long lifetime", issuedAt: issued, lifetime: 24 * time.Hour, want: RefreshMargin},
+		{name: "lifetime of four margins", issuedAt: issued, lifetime: 4 * RefreshMargin, want: RefreshMargin},
+		{name: "short lifetime", issuedAt: issued, lifetime: 8 * time.Minute, want: 2 * time.Minute},
+		{name: "unknown issue time", lifetime: 8 * time.Minute, want: RefreshMargin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt := issued.Add(tt.lifetime)
+			if got := expiresAt.Sub(refreshAt(tt.issuedAt, expiresAt)); got != tt.want {
+				t.Errorf("refreshAt is %s before expiry, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// encryptedMagic identifies the format of encrypted cache files
+	encryptedMagic = "VICOENC1"
+	// saltSize is the size of the random salt the key is derived with
+	saltSize = 16
+	// keySize selects AES-256
+	keySize = 32
+	// kdfIterations is the PBKDF2 iteration count used to derive the key
+	kdfIterations = 200000
+)
+
+// ErrDecrypt is returned when an encrypted cache file cannot be decrypted, typically
+// because it was written with a different passphrase or key file.
+var ErrDecrypt = errors.New("error decrypting token cache: wrong passphrase or key file, or corrupted file")
+
+// EncryptedFileStore keeps the token cache in a file encrypted with AES-256-GCM.
+// The key is derived from a secret with PBKDF2-HMAC-SHA256 and a random salt that is
+// stored in the file, together with the nonce. The file layout is:
+//
+//	"VICOENC1" | salt (16 bytes) | nonce (12 bytes) | ciphertext and tag
+type EncryptedFileStore struct {
+	file FileStore
+	// secret is the passphrase or key file contents the key is derived from
+	secret []byte
+
+	// The key derived for salt, kept to avoid deriving it again on every write
+	mu   sync.Mutex
+	salt []byte
+	key  []byte
+}
+
+// NewEncryptedFileStore creates a store that keeps the token cache encrypted at path,
+// with a key derived from secret.
+func NewEncryptedFileStore(path string, secret []byte) *EncryptedFileStore {
+	return &EncryptedFileStore{
+		file:   FileStore{Path: path},
+		secret: append([]byte(nil), secret...),
+	}
+}
+
+// Read decrypts and returns the contents of the cache file, or nil if it does not exist.
+// It returns ErrDecrypt if the file cannot be decrypted with the store's secret.
+func (s *EncryptedFileStore) Read() ([]byte, error) {
+	data, err := s.file.Read()
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	header := len(encryptedMagic) + saltSize
+	if len(data) < header || string(data[:len(encryptedMagic)]) != encryptedMagic {
+		return nil, ErrDecrypt
+	}
+	salt := data[len(encryptedMagic):header]
+
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := data[header:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	// The header is authenticated along with the ciphertext
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, data[:header])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// Write encrypts data and replaces the contents of the cache file with it.
+// A fresh nonce is used for every write.
+func (s *EncryptedFileStore) Write(data []byte) error {
+	s.mu.Lock()
+	salt := s.salt
+	s.mu.Unlock()
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("error generating salt: %w", err)
+		}
+	}
+
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	// The header is copied into out, as Seal's output must not overlap its additional data
+	header := append([]byte(encryptedMagic), salt...)
+	out := make([]byte, 0, len(header)+len(nonce)+len(data)+gcm.Overhead())
+	out = append(append(out, header...), nonce...)
+	out = gcm.Seal(out, nonce, data, header)
+
+	return s.file.Write(out)
+}
+
+// Remove deletes the cache file.
+func (s *EncryptedFileStore) Remove() error {
+	return s.file.Remove()
+}
+
+// cipher returns the AES-GCM cipher keyed for salt, deriving the key if needed.
+func (s *EncryptedFileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	s.mu.Lock()
+	if s.key == nil || !bytes.Equal(s.salt, salt) {
+		s.salt = append([]byte(nil), salt...)
+		s.key = pbkdf2.Key(s.secret, s.salt, kdfIterations, keySize, sha256.New)
+	}
+	key := s.key
+	s.mu.Unlock()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// LoadOrCreateKeyFile reads the hex-encoded key in path, creating the file with a new
+// random key if it does not exist. The key file is only readable by its owner. A new
+// key is written to a temporary file that is then linked into place, so that other
+// processes never read a partially written key file. If another process creates the
+// key file first, its key is used instead.
+//
+// Parameters:
+//   - path: The path of the key file
+//
+// Returns:
+//   - []byte: The key
+//   - error: Any error encountered reading or creating the key file
+func LoadOrCreateKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) == 0 {
+			return nil, fmt.Errorf("error reading key file %s: not a hex-encoded key", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading key file: %w", err)
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error creating key directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("error creating key file: %w", err)
+	}
+	// The temporary file is no longer needed once it is linked into place
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("error creating key file: %w", err)
+	}
+	if _, err := tmp.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("error writing key file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("error writing key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("error writing key file: %w", err)
+	}
+
+	// Unlike a rename, a link does not replace a key file created concurrently by
+	// another process, whose key may already be in use
+	if err := os.Link(tmp.Name(), path); err != nil {
+		if os.IsExist(err) {
+			return LoadOrCreateKeyFile(path)
+		}
+		return nil, fmt.Errorf("error creating key file: %w", err)
+	}
+	return key, nil
+}
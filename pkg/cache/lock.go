@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/logging"
+)
+
+// DefaultLockTimeout is how long a process waits for another process to release the
+// token cache lock, for example while it logs in, before giving up, unless the
+// manager's LockTimeout says otherwise.
+const DefaultLockTimeout = 30 * time.Second
+
+// ErrLockTimeout is returned when the token cache lock is still held by another
+// process after the lock timeout.
+var ErrLockTimeout = errors.New("timed out waiting for the token cache lock")
+
+// lockRetryInterval is how often a held lock is tried again.
+const lockRetryInterval = 50 * time.Millisecond
+
+// lockFile acquires an exclusive lock on the file at path, creating it if needed,
+// and waits up to timeout for another process or goroutine to release it. Waiting
+// ends early with ctx's error when ctx is done. The returned function releases the lock.
+func lockFile(ctx context.Context, path string, timeout time.Duration) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	for waited := false; ; waited = true {
+		unlock, acquired, err := tryLockFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error locking token cache: %w", err)
+		}
+		if acquired {
+			return unlock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w %s after %s", ErrLockTimeout, path, timeout)
+		}
+		if !waited {
+			logging.Logger().DebugContext(ctx, "waiting for token cache lock held by another process", "path", path)
+		}
+
+		timer := time.NewTimer(lockRetryInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("error waiting for the token cache lock: %w", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cache
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge is the age after which a lock file is assumed to be left behind by
+// a process that exited without removing it. It is well above the time a login with
+// the default retry policy can hold the lock.
+const staleLockAge = 15 * time.Minute
+
+// tryLockFile locks by creating the file at path exclusively, since flock(2) is not
+// available on this platform. Unlocking removes the file.
+func tryLockFile(path string) (func(), bool, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	f.Close()
+
+	return func() {
+		os.Remove(path)
+	}, true, nil
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithLock(t *testing.T) {
+	dir := t.TempDir()
+	holder := newTestManager(dir, "me@example.com")
+
+	err := holder.WithLock(context.Background(), func() error {
+		// The manager's own methods do not wait for the lock it holds
+		if err := holder.SaveToken("token", 1); err != nil {
+			return err
+		}
+
+		waiter := newTestManager(dir, "me@example.com")
+		waiter.LockTimeout = 100 * time.Millisecond
+		err := waiter.WithLock(context.Background(), func() error { return nil })
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Errorf("WithLock while the lock is held = %v, want ErrLockTimeout", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		waiter.LockTimeout = time.Hour
+		start := time.Now()
+		err = waiter.WithLock(ctx, func() error { return nil })
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WithLock with an expiring context = %v, want context.DeadlineExceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("WithLock waited %s after the context was done", elapsed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock is free again once fn returns
+	fnErr := errors.New("fn failed")
+	if err := newTestManager(dir, "").WithLock(context.Background(), func() error { return fnErr }); err != fnErr {
+		t.Errorf("WithLock after the lock was released = %v, want the error of fn", err)
+	}
+}
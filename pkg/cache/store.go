@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Token store kinds accepted by NewTokenStore and the VICOHOME_TOKEN_STORE environment variable.
+const (
+	StoreFile      = "file"      // Plaintext JSON file
+	StoreEncrypted = "encrypted" // AES-GCM encrypted file
+	StoreMemory    = "memory"    // Process memory, nothing is written to disk
+)
+
+// TokenStore persists the serialized token cache.
+// Implementations decide where and how the data is kept, so that the plaintext file,
+// the encrypted file and the in-memory store are interchangeable.
+type TokenStore interface {
+	// Read returns the stored data, or nil if nothing has been stored.
+	Read() ([]byte, error)
+	// Write replaces the stored data.
+	Write(data []byte) error
+	// Remove discards the stored data. Removing an empty store is not an error.
+	Remove() error
+}
+
+// FileStore keeps the token cache as a plaintext file readable only by its owner.
+type FileStore struct {
+	Path string // Full path to the cache file
+}
+
+// Read returns the contents of the cache file, or nil if it does not exist.
+func (s *FileStore) Read() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token cache: %w", err)
+	}
+	return data, nil
+}
+
+// Write replaces the contents of the cache file atomically: the data is written to a
+// temporary file in the same directory, which is then renamed over the cache file, so
+// that readers never see a partially written cache.
+func (s *FileStore) Write(data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), "."+filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	// Remove the temporary file if anything below fails; after the rename it is gone
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the cache file.
+func (s *FileStore) Remove() error {
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing token cache: %w", err)
+	}
+	return nil
+}
+
+// MemoryStore keeps the token cache in memory. Tokens are lost when the process exits,
+// which suits tests and CI jobs that must not leave credentials on disk.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// Read returns a copy of the stored data.
+func (s *MemoryStore) Read() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, nil
+	}
+	return append([]byte(nil), s.data...), nil
+}
+
+// Write replaces the stored data with a copy of data.
+func (s *MemoryStore) Write(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = append([]byte(nil), data...)
+	return nil
+}
+
+// Remove discards the stored data.
+func (s *MemoryStore) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = nil
+	return nil
+}
+
+// processStore is the in-memory store shared by the token cache managers of a process.
+var processStore = &MemoryStore{}
+
+// NewTokenStore creates the token store of the given kind in cacheDir.
+//
+// The encrypted store derives its key from the VICOHOME_CACHE_PASSPHRASE environment
+// variable if it is set, and otherwise from a machine-local key file, which is
+// VICOHOME_CACHE_KEY_FILE or cacheDir/cache.key. A missing key file is created with a
+// random key.
+//
+// The default key file sits next to the encrypted cache, so the encrypted store gives
+// no protection against anyone who can read cacheDir: it only helps when the
+// passphrase comes from the environment or the key file is kept elsewhere, for
+// example on a separate volume or in a secrets mount.
+//
+// Parameters:
+//   - kind: The store kind (StoreFile, StoreEncrypted or StoreMemory); "" selects StoreFile
+//   - cacheDir: The directory the cache and key files are kept in
+//
+// Returns:
+//   - TokenStore: The configured store
+//   - error: An error if the kind is unknown or the key file cannot be read or created
+func NewTokenStore(kind, cacheDir string) (TokenStore, error) {
+	switch kind {
+	case "", StoreFile:
+		return &FileStore{Path: filepath.Join(cacheDir, "auth.json")}, nil
+	case StoreEncrypted:
+		secret := []byte(os.Getenv("VICOHOME_CACHE_PASSPHRASE"))
+		if len(secret) == 0 {
+			keyFile := os.Getenv("VICOHOME_CACHE_KEY_FILE")
+			if keyFile == "" {
+				keyFile = filepath.Join(cacheDir, "cache.key")
+			}
+			var err error
+			secret, err = LoadOrCreateKeyFile(keyFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewEncryptedFileStore(filepath.Join(cacheDir, "auth.enc"), secret), nil
+	case StoreMemory:
+		return processStore, nil
+	default:
+		return nil, fmt.Errorf("unknown token store %q (expected %s, %s or %s)", kind, StoreFile, StoreEncrypted, StoreMemory)
+	}
+}
@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "auth.json")}
+	testStoreRoundTrip(t, store)
+
+	if _, err := os.Stat(store.Path); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Remove: %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreRoundTrip(t, &MemoryStore{})
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.enc")
+	testStoreRoundTrip(t, NewEncryptedFileStore(path, []byte("passphrase")))
+}
+
+// testStoreRoundTrip checks that a store starts empty, returns what was written and
+// is empty again after Remove.
+func testStoreRoundTrip(t *testing.T, store TokenStore) {
+	t.Helper()
+
+	if data, err := store.Read(); err != nil || data != nil {
+		t.Fatalf("Read of a new store = %q, %v, want nil, nil", data, err)
+	}
+
+	for _, want := range [][]byte{[]byte(`{"tokens":{}}`), []byte(`{"tokens":{"a":{}}}`)} {
+		if err := store.Write(want); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		got, err := store.Read()
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("Read = %q, want %q", got, want)
+		}
+	}
+
+	if err := store.Remove(); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := store.Remove(); err != nil {
+		t.Fatalf("Remove of a removed store failed: %v", err)
+	}
+	if data, err := store.Read(); err != nil || data != nil {
+		t.Fatalf("Read after Remove = %q, %v, want nil, nil", data, err)
+	}
+}
+
+func TestEncryptedFileStoreEncrypts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.enc")
+	plaintext := []byte(`{"tokens":{"me":{"token":"secret-token"}}}`)
+	if err := NewEncryptedFileStore(path, []byte("right")).Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret-token")) {
+		t.Error("encrypted cache file contains the token in plaintext")
+	}
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		t.Errorf("encrypted cache file does not start with %q", encryptedMagic)
+	}
+
+	// A new store with the same secret derives the same key from the stored salt
+	got, err := NewEncryptedFileStore(path, []byte("right")).Read()
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Read with the right secret = %q, %v", got, err)
+	}
+
+	if _, err := NewEncryptedFileStore(path, []byte("wrong")).Read(); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Read with the wrong secret error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestEncryptedFileStoreRejectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.enc")
+	store := NewEncryptedFileStore(path, []byte("secret"))
+	if err := store.Write([]byte(`{"tokens":{}}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]byte{
+		"flipped ciphertext": append(append([]byte(nil), data[:len(data)-1]...), data[len(data)-1]^1),
+		"flipped salt":       append(append(append([]byte(nil), data[:len(encryptedMagic)]...), data[len(encryptedMagic)]^1), data[len(encryptedMagic)+1:]...),
+		"truncated":          data[:len(encryptedMagic)+saltSize+4],
+		"plaintext cache":    []byte(`{"tokens":{}}`),
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(path, tampered, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewEncryptedFileStore(path, []byte("secret")).Read(); !errors.Is(err, ErrDecrypt) {
+				t.Errorf("Read error = %v, want ErrDecrypt", err)
+			}
+		})
+	}
+}
+
+func TestLoadOrCreateKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "cache.key")
+
+	key, err := LoadOrCreateKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != keySize {
+		t.Errorf("created key has %d bytes, want %d", len(key), keySize)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file mode = %o, want 600", perm)
+	}
+
+	again, err := LoadOrCreateKeyFile(path)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Errorf("second LoadOrCreateKeyFile = %x, %v, want the created key", again, err)
+	}
+
+	if err := os.WriteFile(path, []byte("not hex\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKeyFile(path); err == nil {
+		t.Error("LoadOrCreateKeyFile accepted a malformed key file")
+	}
+}
+
+func TestLoadOrCreateKeyFileConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.key")
+
+	const callers = 16
+	keys := make([][]byte, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys[i], errs[i] = LoadOrCreateKeyFile(path)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if !bytes.Equal(keys[i], keys[0]) {
+			t.Fatalf("caller %d got key %x, caller 0 got %x", i, keys[i], keys[0])
+		}
+	}
+
+	stored, err := LoadOrCreateKeyFile(path)
+	if err != nil || !bytes.Equal(stored, keys[0]) {
+		t.Errorf("key file holds %x, %v, want the key every caller got", stored, err)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*.tmp")); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestNewTokenStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("VICOHOME_CACHE_PASSPHRASE", "")
+	t.Setenv("VICOHOME_CACHE_KEY_FILE", "")
+
+	tests := []struct {
+		kind string
+		want TokenStore
+	}{
+		{"", &FileStore{}},
+		{StoreFile, &FileStore{}},
+		{StoreEncrypted, &EncryptedFileStore{}},
+		{StoreMemory, &MemoryStore{}},
+	}
+	for _, tt := range tests {
+		store, err := NewTokenStore(tt.kind, dir)
+		if err != nil {
+			t.Fatalf("NewTokenStore(%q) error = %v", tt.kind, err)
+		}
+		if got, want := fmt.Sprintf("%T", store), fmt.Sprintf("%T", tt.want); got != want {
+			t.Errorf("NewTokenStore(%q) = %s, want %s", tt.kind, got, want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "cache.key")); err != nil {
+		t.Errorf("encrypted store did not create the default key file: %v", err)
+	}
+	if _, err := NewTokenStore("vault", dir); err == nil {
+		t.Error("NewTokenStore accepted an unknown kind")
+	}
+}
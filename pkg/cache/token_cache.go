@@ -1,35 +1,77 @@
 // Package cache provides caching functionality for authentication tokens.
 //
-// This package implements file-based caching of authentication tokens in the user's
-// home directory, with support for token expiration and management operations.
+// This package implements caching of authentication tokens in the user's home
+// directory, with support for token expiration and management operations.
+// Tokens are kept per account and API endpoint, so that several accounts and regions
+// can be used side by side without sharing a token. Where the cache is kept is
+// decided by a TokenStore: a plaintext file, an encrypted file or process memory.
+// Changes to the cache are made under a lock file, so that several processes can
+// share the cache safely.
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/dydx/vico-cli/pkg/logging"
 )
 
-// TokenCache represents the structure of the token cache file.
+// TokenCache represents a cached token.
 // It stores both the authentication token and its expiration time.
 type TokenCache struct {
-	Token     string    `json:"token"`      // The authentication token
-	ExpiresAt time.Time `json:"expires_at"` // When the token expires
+	Token     string    `json:"token"`               // The authentication token
+	ExpiresAt time.Time `json:"expires_at"`          // When the token expires
+	IssuedAt  time.Time `json:"issued_at,omitempty"` // When the token was obtained
+	Email     string    `json:"email,omitempty"`     // The account the token belongs to
+	BaseURL   string    `json:"base_url,omitempty"`  // The API endpoint that issued the token
+}
+
+// cacheIndex is the structure of the token cache file.
+type cacheIndex struct {
+	// Tokens by account key, see AccountKey
+	Tokens map[string]TokenCache `json:"tokens"`
+	// Account key of the account last saved for each base URL
+	Current map[string]string `json:"current"`
 }
 
-// TokenCacheManager handles reading and writing authentication tokens to a cache file.
+// TokenCacheManager handles reading and writing authentication tokens to a token store.
 // It provides methods for saving, retrieving, and clearing tokens, with support for
-// automatic expiration checking.
+// automatic expiration checking. The methods act on the token of the account selected
+// by Email and BaseURL.
 type TokenCacheManager struct {
-	CacheDir  string // Directory where cache files are stored
-	CacheFile string // Full path to the token cache file
+	CacheDir string     // Directory where cache files are stored
+	Store    TokenStore // Where the token cache is kept
+
+	// Email selects the account whose token is read and written. If it is empty, the
+	// account whose token was last saved for BaseURL is used.
+	Email   string
+	BaseURL string // API endpoint the account's token is used with
+
+	// LockTimeout is how long to wait for another process to release the cache lock.
+	// It should cover the longest time a process holds the lock, such as a login with
+	// all its retries. Zero selects DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	// held is set while the manager holds the cache lock
+	held bool
 }
 
+// processLock serializes cache changes within the process when a manager has no
+// cache directory to keep a lock file in.
+var processLock sync.Mutex
+
 // NewTokenCacheManager creates a new token cache manager.
 // It sets up the cache directory in the user's home directory if it doesn't already exist.
-// The cache location is ~/.vicohome/auth.json
+// The token store is selected by the VICOHOME_TOKEN_STORE environment variable (file,
+// encrypted or memory, see NewTokenStore). The plaintext cache is ~/.vicohome/auth.json
+// and the encrypted cache is ~/.vicohome/auth.enc.
 //
 // Returns:
 //   - *TokenCacheManager: The configured cache manager if successful
@@ -47,12 +89,43 @@ func NewTokenCacheManager() (*TokenCacheManager, error) {
 		return nil, fmt.Errorf("error creating cache directory: %w", err)
 	}
 
+	store, err := NewTokenStore(os.Getenv("VICOHOME_TOKEN_STORE"), cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TokenCacheManager{
-		CacheDir:  cacheDir,
-		CacheFile: filepath.Join(cacheDir, "auth.json"),
+		CacheDir: cacheDir,
+		Store:    store,
 	}, nil
 }
 
+// NewAccountTokenCacheManager creates a token cache manager like NewTokenCacheManager
+// that acts on the token of the given account and API endpoint.
+//
+// Parameters:
+//   - email: The account's email address, or "" for the account last saved for baseURL
+//   - baseURL: The API endpoint the token is used with
+//
+// Returns:
+//   - *TokenCacheManager: The configured cache manager if successful
+//   - error: Any error encountered during setup
+func NewAccountTokenCacheManager(email, baseURL string) (*TokenCacheManager, error) {
+	m, err := NewTokenCacheManager()
+	if err != nil {
+		return nil, err
+	}
+	m.Email = email
+	m.BaseURL = baseURL
+	return m, nil
+}
+
+// AccountKey returns the key under which the token of an account is cached.
+// Email addresses are compared case-insensitively.
+func AccountKey(email, baseURL string) string {
+	return strings.ToLower(email) + " " + strings.TrimRight(baseURL, "/")
+}
+
 // SaveToken saves an authentication token to the cache file with an expiration time.
 // The token is stored along with its expiration time calculated from the current time
 // plus the specified duration in hours.
@@ -64,29 +137,59 @@ func NewTokenCacheManager() (*TokenCacheManager, error) {
 // Returns:
 //   - error: Any error encountered during the save operation
 func (m *TokenCacheManager) SaveToken(token string, durationHours int) error {
+	return m.SaveAccountToken(m.Email, token, durationHours)
+}
+
+// SaveAccountToken saves an authentication token like SaveToken, for the account
+// with the given email address. The account becomes the one used for BaseURL when
+// no email is selected.
+//
+// Parameters:
+//   - email: The email address of the account the token belongs to
+//   - token: The authentication token to save
+//   - durationHours: How long the token should be considered valid, in hours
+//
+// Returns:
+//   - error: Any error encountered during the save operation
+func (m *TokenCacheManager) SaveAccountToken(email, token string, durationHours int) error {
 	// Default to 24 hours if not specified
 	if durationHours <= 0 {
 		durationHours = 24
 	}
 
-	// Create token cache object
-	tokenCache := TokenCache{
-		Token:     token,
-		ExpiresAt: time.Now().Add(time.Duration(durationHours) * time.Hour),
-	}
+	return m.SaveAccountTokenUntil(email, token, time.Now().Add(time.Duration(durationHours)*time.Hour))
+}
 
-	// Marshal to JSON
-	cacheData, err := json.Marshal(tokenCache)
+// SaveAccountTokenUntil saves an authentication token like SaveAccountToken, with a
+// known expiration time.
+//
+// Parameters:
+//   - email: The email address of the account the token belongs to
+//   - token: The authentication token to save
+//   - expiresAt: When the token expires
+//
+// Returns:
+//   - error: Any error encountered during the save operation
+func (m *TokenCacheManager) SaveAccountTokenUntil(email, token string, expiresAt time.Time) error {
+	unlock, err := m.lock(context.Background())
 	if err != nil {
-		return fmt.Errorf("error marshaling token cache: %w", err)
+		return err
 	}
+	defer unlock()
 
-	// Write to file
-	if err := os.WriteFile(m.CacheFile, cacheData, 0600); err != nil {
-		return fmt.Errorf("error writing token cache: %w", err)
+	index := m.readIndex()
+	key := AccountKey(email, m.BaseURL)
+	index.Tokens[key] = TokenCache{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		IssuedAt:  time.Now(),
+		Email:     email,
+		BaseURL:   strings.TrimRight(m.BaseURL, "/"),
 	}
+	index.Current[strings.TrimRight(m.BaseURL, "/")] = key
 
-	return nil
+	logging.Logger().Debug("saving token to cache", "base_url", strings.TrimRight(m.BaseURL, "/"), "expires_at", expiresAt.Format(time.RFC3339))
+	return m.writeIndex(index)
 }
 
 // GetToken retrieves a token from the cache file if it exists and is not expired.
@@ -97,23 +200,8 @@ func (m *TokenCacheManager) SaveToken(token string, durationHours int) error {
 //   - string: The cached token if valid
 //   - bool: True if a valid token was found, false otherwise
 func (m *TokenCacheManager) GetToken() (string, bool) {
-	// Check if cache file exists
-	_, err := os.Stat(m.CacheFile)
-	if os.IsNotExist(err) {
-		return "", false
-	}
-
-	// Read cache file
-	cacheData, err := os.ReadFile(m.CacheFile)
-	if err != nil {
-		// If there's an error reading, treat as if no cache exists
-		return "", false
-	}
-
-	// Unmarshal data
-	var tokenCache TokenCache
-	if err := json.Unmarshal(cacheData, &tokenCache); err != nil {
-		// If there's an error unmarshaling, treat as if no cache exists
+	tokenCache, ok := m.Load()
+	if !ok {
 		return "", false
 	}
 
@@ -126,22 +214,180 @@ func (m *TokenCacheManager) GetToken() (string, bool) {
 	return tokenCache.Token, true
 }
 
-// ClearToken removes the token cache file if it exists.
+// Load reads the selected account's cached token, including tokens that have already expired.
+//
+// Returns:
+//   - TokenCache: The cached token and its details
+//   - bool: True if a token was found for the account, false otherwise
+func (m *TokenCacheManager) Load() (TokenCache, bool) {
+	index := m.readIndex()
+	tokenCache, ok := index.Tokens[m.key(index)]
+	return tokenCache, ok
+}
+
+// List returns every cached token, ordered by endpoint and email address.
+func (m *TokenCacheManager) List() []TokenCache {
+	index := m.readIndex()
+	tokens := make([]TokenCache, 0, len(index.Tokens))
+	for _, tokenCache := range index.Tokens {
+		tokens = append(tokens, tokenCache)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].BaseURL != tokens[j].BaseURL {
+			return tokens[i].BaseURL < tokens[j].BaseURL
+		}
+		return tokens[i].Email < tokens[j].Email
+	})
+	return tokens
+}
+
+// ClearToken removes the selected account's token from the cache.
 // This is typically called when a token is known to be invalid or expired.
+// The cache file is removed once it holds no tokens.
 //
 // Returns:
 //   - error: Any error encountered during the removal operation
 func (m *TokenCacheManager) ClearToken() error {
-	_, err := os.Stat(m.CacheFile)
-	if os.IsNotExist(err) {
-		// File doesn't exist, nothing to clear
+	unlock, err := m.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	index := m.readIndex()
+	key := m.key(index)
+	if _, ok := index.Tokens[key]; !ok {
+		// Nothing cached for the account, nothing to clear
 		return nil
 	}
 
-	// Remove the cache file
-	if err := os.Remove(m.CacheFile); err != nil {
-		return fmt.Errorf("error removing token cache: %w", err)
+	delete(index.Tokens, key)
+	for baseURL, current := range index.Current {
+		if current == key {
+			delete(index.Current, baseURL)
+		}
+	}
+
+	logging.Logger().Debug("removing token from cache", "base_url", strings.TrimRight(m.BaseURL, "/"))
+	if len(index.Tokens) == 0 {
+		return m.ClearAll()
+	}
+	return m.writeIndex(index)
+}
+
+// ClearAll removes the token cache from the store, discarding the tokens of all accounts.
+//
+// Returns:
+//   - error: Any error encountered during the removal operation
+func (m *TokenCacheManager) ClearAll() error {
+	unlock, err := m.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	logging.Logger().Debug("removing token cache")
+	return m.Store.Remove()
+}
+
+// WithLock runs fn while holding the token cache lock, waiting up to the manager's
+// LockTimeout for other processes to release it. No other process changes the cache while fn runs, so
+// fn can check the cache and update it based on what it found, for example to log in
+// only if no other process has done so in the meantime. The manager's methods can be
+// used within fn, but the manager must not be shared with other goroutines until fn
+// returns. Waiting for the lock stops when ctx is done.
+//
+// Parameters:
+//   - ctx: Cancels waiting for the lock when done
+//   - fn: The function to run while holding the lock
+//
+// Returns:
+//   - error: The error returned by fn, or an error if the lock could not be acquired,
+//     which wraps ErrLockTimeout if another process held it for too long, or ctx's
+//     error if ctx was done first
+func (m *TokenCacheManager) WithLock(ctx context.Context, fn func() error) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// lock acquires the cache lock, unless the manager already holds it, waiting until
+// the lock timeout or until ctx is done. The returned function releases the lock.
+func (m *TokenCacheManager) lock(ctx context.Context) (func(), error) {
+	if m.held {
+		return func() {}, nil
+	}
+
+	if m.CacheDir == "" {
+		processLock.Lock()
+		m.held = true
+		return func() {
+			m.held = false
+			processLock.Unlock()
+		}, nil
+	}
+
+	timeout := m.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	unlock, err := lockFile(ctx, filepath.Join(m.CacheDir, "auth.lock"), timeout)
+	if err != nil {
+		return nil, err
+	}
+	m.held = true
+	return func() {
+		m.held = false
+		unlock()
+	}, nil
+}
+
+// key returns the account key selected by Email and BaseURL.
+func (m *TokenCacheManager) key(index cacheIndex) string {
+	if m.Email == "" {
+		if current, ok := index.Current[strings.TrimRight(m.BaseURL, "/")]; ok {
+			return current
+		}
+	}
+	return AccountKey(m.Email, m.BaseURL)
+}
+
+// readIndex reads the token cache from the store. A missing or unreadable cache,
+// including one encrypted with another key, or one written by an older version that
+// cached a single token, yields an empty index.
+func (m *TokenCacheManager) readIndex() cacheIndex {
+	index := cacheIndex{}
+	cacheData, err := m.Store.Read()
+	if err != nil {
+		logging.Logger().Warn("could not read token cache, ignoring it", logging.Error(err))
+	} else if cacheData != nil {
+		// If there's an error unmarshaling, treat as if no cache exists
+		if err := json.Unmarshal(cacheData, &index); err != nil {
+			logging.Logger().Warn("token cache is corrupt, ignoring it", logging.Error(err))
+			index = cacheIndex{}
+		}
+	}
+
+	if index.Tokens == nil {
+		index.Tokens = make(map[string]TokenCache)
+	}
+	if index.Current == nil {
+		index.Current = make(map[string]string)
+	}
+	return index
+}
+
+// writeIndex writes the token cache to the store.
+func (m *TokenCacheManager) writeIndex(index cacheIndex) error {
+	// Marshal to JSON
+	cacheData, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("error marshaling token cache: %w", err)
 	}
 
-	return nil
+	return m.Store.Write(cacheData)
 }
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestManager returns a manager for the account with the given email address at
+// api.example.com, keeping its cache in dir.
+func newTestManager(dir, email string) *TokenCacheManager {
+	return &TokenCacheManager{
+		CacheDir: dir,
+		Store:    &FileStore{Path: filepath.Join(dir, "auth.json")},
+		Email:    email,
+		BaseURL:  "https://api.example.com/",
+	}
+}
+
+func TestTokenCacheManagerAccounts(t *testing.T) {
+	dir := t.TempDir()
+	alice := newTestManager(dir, "alice@example.com")
+	bob := newTestManager(dir, "Bob@Example.com")
+
+	if _, ok := alice.GetToken(); ok {
+		t.Fatal("GetToken of an empty cache found a token")
+	}
+
+	if err := alice.SaveToken("alice-token", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.SaveToken("bob-token", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if token, ok := alice.GetToken(); !ok || token != "alice-token" {
+		t.Errorf("GetToken for alice = %q, %t", token, ok)
+	}
+	// Email addresses are compared case-insensitively
+	if token, ok := newTestManager(dir, "bob@example.com").GetToken(); !ok || token != "bob-token" {
+		t.Errorf("GetToken for bob = %q, %t", token, ok)
+	}
+	// Without an email, the account saved last for the endpoint is used
+	if token, ok := newTestManager(dir, "").GetToken(); !ok || token != "bob-token" {
+		t.Errorf("GetToken without an email = %q, %t, want the last saved account", token, ok)
+	}
+
+	other := newTestManager(dir, "alice@example.com")
+	other.BaseURL = "https://api-eu.example.com"
+	if _, ok := other.GetToken(); ok {
+		t.Error("GetToken found a token saved for another endpoint")
+	}
+
+	tokens := alice.List()
+	if len(tokens) != 2 || tokens[0].Email != "Bob@Example.com" || tokens[1].Email != "alice@example.com" {
+		t.Fatalf("List = %+v, want the tokens of bob and alice", tokens)
+	}
+	if tokens[0].BaseURL != "https://api.example.com" {
+		t.Errorf("BaseURL = %q, want it without the trailing slash", tokens[0].BaseURL)
+	}
+
+	if err := bob.ClearToken(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bob.GetToken(); ok {
+		t.Error("GetToken found bob's token after ClearToken")
+	}
+	if _, ok := newTestManager(dir, "").GetToken(); ok {
+		t.Error("GetToken without an email still selects the cleared account")
+	}
+	if _, ok := alice.GetToken(); !ok {
+		t.Error("ClearToken of bob removed alice's token")
+	}
+
+	if err := alice.ClearAll(); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := alice.List(); len(tokens) != 0 {
+		t.Errorf("List after ClearAll = %+v, want no tokens", tokens)
+	}
+}
+
+func TestTokenCacheManagerExpiry(t *testing.T) {
+	m := newTestManager(t.TempDir(), "me@example.com")
+	if err := m.SaveAccountTokenUntil(m.Email, "old-token", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.GetToken(); ok {
+		t.Error("GetToken returned an expired token")
+	}
+	if tokenCache, ok := m.Load(); !ok || tokenCache.Token != "old-token" {
+		t.Errorf("Load = %+v, %t, want the expired token", tokenCache, ok)
+	}
+}
+
+func TestTokenCacheManagerIgnoresCorruptCache(t *testing.T) {
+	m := newTestManager(t.TempDir(), "me@example.com")
+	if err := m.Store.Write([]byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.Load(); ok {
+		t.Error("Load found a token in a corrupt cache")
+	}
+	if err := m.SaveToken("token", 1); err != nil {
+		t.Fatal(err)
+	}
+	if token, ok := m.GetToken(); !ok || token != "token" {
+		t.Errorf("GetToken after overwriting a corrupt cache = %q, %t", token, ok)
+	}
+}
@@ -0,0 +1,318 @@
+// Package client provides a typed client for the Vicohome API.
+//
+// The Client type wraps the HTTP plumbing shared by every endpoint: building
+// requests against a base URL, attaching the authentication token, refreshing
+// the token when the API rejects it, retrying requests that fail for transient
+// reasons, and decoding the response envelope. It is used by the CLI commands
+// and can be imported directly by other Go programs.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/logging"
+	"github.com/dydx/vico-cli/pkg/ratelimit"
+	"github.com/dydx/vico-cli/pkg/retry"
+)
+
+// DefaultBaseURL is the API endpoint used when no other base URL is configured.
+const DefaultBaseURL = auth.DefaultBaseURL
+
+// DefaultRegion is the region whose endpoint is DefaultBaseURL.
+const DefaultRegion = "us"
+
+// DefaultRequestTimeout bounds each API request made by a Client created with New,
+// so that a hung connection cannot block the client forever.
+const DefaultRequestTimeout = auth.RequestTimeout
+
+// Regions maps the supported region names to their API base URLs.
+var Regions = map[string]string{
+	"us": "https://api-us.vicohome.io",
+	"eu": "https://api-eu.vicohome.io",
+}
+
+// ResolveBaseURL determines the API base URL from a region name and an explicit URL.
+// An explicit URL always wins, which allows pointing the client at a custom or local
+// endpoint. Otherwise the region is looked up in Regions; an empty region selects
+// DefaultRegion.
+//
+// Parameters:
+//   - region: The region name (e.g., "us" or "eu")
+//   - apiURL: An explicit base URL, or "" to use the region
+//
+// Returns:
+//   - string: The base URL without a trailing slash
+//   - error: An error if the URL is malformed or the region is unknown
+func ResolveBaseURL(region, apiURL string) (string, error) {
+	if apiURL != "" {
+		u, err := url.Parse(apiURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", fmt.Errorf("invalid API URL %q: must be an absolute http(s) URL", apiURL)
+		}
+		return strings.TrimRight(apiURL, "/"), nil
+	}
+
+	if region == "" {
+		region = DefaultRegion
+	}
+	baseURL, ok := Regions[strings.ToLower(region)]
+	if !ok {
+		names := make([]string, 0, len(Regions))
+		for name := range Regions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown region %q (supported: %s)", region, strings.Join(names, ", "))
+	}
+	return baseURL, nil
+}
+
+// TokenSource supplies authentication tokens to a Client.
+// Token returns the current token, while Refresh is called when the API reports
+// that the current token is no longer accepted and must return a new one. Both
+// should give up when ctx is done.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, error)
+}
+
+// ExpiringTokenSource is implemented by TokenSources that know when their tokens
+// expire. RefreshAt returns when the token last returned should be replaced, or the
+// zero time if that is unknown. The client then asks for a new token with Token
+// instead of waiting for the API to reject the old one.
+type ExpiringTokenSource interface {
+	TokenSource
+	RefreshAt() time.Time
+}
+
+// StaticToken is a TokenSource that always returns the same token.
+// It cannot be refreshed, which makes it suitable for short-lived programs
+// that already hold a valid token.
+type StaticToken string
+
+// Token returns the static token.
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Refresh always fails because a static token cannot be renewed.
+func (t StaticToken) Refresh(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("static token cannot be refreshed")
+}
+
+// Defaults for paging through event lists.
+const (
+	DefaultPageSize    = 100            // Events requested per page
+	DefaultEventWindow = 24 * time.Hour // Longest time range requested at once
+
+	// maxPagesPerWindow guards against endless paging if the API misbehaves.
+	maxPagesPerWindow = 1000
+)
+
+// Client is a Vicohome API client.
+// The zero value is not usable; create clients with New.
+type Client struct {
+	BaseURL     string             // API base URL, e.g. https://api-us.vicohome.io
+	HTTPClient  *http.Client       // HTTP client used to send requests
+	Auth        TokenSource        // Source of authentication tokens
+	Language    string             // Language code sent with requests (e.g., "en")
+	CountryNo   string             // Country code sent with requests (e.g., "US")
+	PageSize    int                // Events requested per page when listing events
+	EventWindow time.Duration      // Longest time range requested at once when listing events
+	Concurrency int                // Number of event windows fetched in parallel
+	Location    *time.Location     // Time zone of event timestamps; nil means time.Local
+	Retry       retry.Policy       // How requests that fail for transient reasons are retried
+	Limiter     *ratelimit.Limiter // Limits the request rate; nil means unlimited
+
+	// The current token is shared by all requests made through the client, so that
+	// concurrent requests rejected for the same stale token trigger a single refresh.
+	tokenMu  sync.Mutex
+	token    string
+	tokenGen int // incremented each time the token is replaced
+}
+
+// New creates a Client that authenticates using the given token source.
+// The client talks to DefaultBaseURL with English/US locale settings; callers
+// may change any of the exported fields before making requests.
+func New(source TokenSource) *Client {
+	return &Client{
+		BaseURL:     DefaultBaseURL,
+		HTTPClient:  &http.Client{Timeout: DefaultRequestTimeout},
+		Auth:        source,
+		Language:    "en",
+		CountryNo:   "US",
+		PageSize:    DefaultPageSize,
+		EventWindow: DefaultEventWindow,
+		Concurrency: 1,
+		Retry:       retry.DefaultPolicy(),
+	}
+}
+
+// call posts the JSON-encoded payload to the given API path and returns the
+// decoded response envelope. If the API rejects the token, the token is
+// refreshed through the client's TokenSource and the request is retried once.
+// The request, its retries and any token refresh are canceled when ctx is done.
+func (c *Client) call(ctx context.Context, path string, payload interface{}) (map[string]interface{}, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	token, gen, err := c.currentToken(ctx)
+	if err != nil {
+		return nil, tokenError(err)
+	}
+
+	respBody, err := c.post(ctx, path, reqBody, token)
+	if err != nil {
+		return nil, err
+	}
+
+	needsRefresh, apiErr := auth.ValidateResponse(respBody)
+	if needsRefresh {
+		token, err = c.refreshToken(ctx, gen)
+		if err != nil {
+			return nil, tokenError(err)
+		}
+
+		respBody, err = c.post(ctx, path, reqBody, token)
+		if err != nil {
+			return nil, err
+		}
+
+		needsRefresh, apiErr = auth.ValidateResponse(respBody)
+		if needsRefresh {
+			return nil, &auth.AuthError{Msg: "token not accepted even after refresh", Err: apiErr}
+		}
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var responseMap map[string]interface{}
+	if err := json.Unmarshal(respBody, &responseMap); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w\nResponse: %s", err, string(respBody))
+	}
+
+	return responseMap, nil
+}
+
+// tokenError classifies an error from the TokenSource as an *auth.AuthError, unless
+// it already is one, is an *auth.NetworkError, or is an *auth.APIError reporting an
+// HTTP error status that persisted through the retries.
+func tokenError(err error) error {
+	var authErr *auth.AuthError
+	var networkErr *auth.NetworkError
+	var apiErr *auth.APIError
+	if errors.As(err, &authErr) || errors.As(err, &networkErr) {
+		return err
+	}
+	if errors.As(err, &apiErr) && apiErr.HTTPStatus != 0 {
+		return err
+	}
+	return &auth.AuthError{Msg: "could not obtain a token", Err: err}
+}
+
+// currentToken returns the token shared by the client's requests, obtaining one
+// from the TokenSource on first use, and again once an ExpiringTokenSource reports
+// that the token is due for refresh. The returned generation identifies the token
+// when it later needs to be refreshed.
+func (c *Client) currentToken(ctx context.Context) (string, int, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != "" {
+		if source, ok := c.Auth.(ExpiringTokenSource); ok {
+			if due := source.RefreshAt(); !due.IsZero() && time.Now().After(due) {
+				logging.Logger().DebugContext(ctx, "token is due for refresh, renewing it")
+				c.token = ""
+			}
+		}
+	}
+
+	if c.token == "" {
+		token, err := c.Auth.Token(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+		c.token = token
+		c.tokenGen++
+	}
+	return c.token, c.tokenGen, nil
+}
+
+// refreshToken replaces the token of the given generation with a fresh one from the
+// TokenSource. If another request has already replaced that token, the replacement
+// is returned without refreshing again.
+func (c *Client) refreshToken(ctx context.Context, gen int) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != "" && c.tokenGen != gen {
+		return c.token, nil
+	}
+
+	token, err := c.Auth.Refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.tokenGen++
+	return token, nil
+}
+
+// post sends an authenticated POST request and returns the raw response body.
+// Each attempt waits for the client's rate limiter, and attempts that fail for
+// transient reasons are repeated according to the client's retry policy.
+func (c *Client) post(ctx context.Context, path string, reqBody []byte, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", token)
+
+	start := time.Now()
+	attempts := 0
+	resp, err := c.Retry.Do(ctx, func() (*http.Response, error) {
+		if attempts++; attempts > 1 {
+			req.Body, _ = req.GetBody()
+		}
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return c.HTTPClient.Do(req)
+	})
+	if err != nil {
+		logging.LogRequest(ctx, req.Method, req.URL.String(), 0, nil, attempts, time.Since(start), err)
+		return nil, &auth.NetworkError{Op: "making request", Err: err}
+	}
+	defer resp.Body.Close()
+
+	if c.Retry.Retryable(resp.StatusCode) {
+		logging.LogRequest(ctx, req.Method, req.URL.String(), resp.StatusCode, nil, attempts, time.Since(start), nil)
+		return nil, &auth.APIError{Msg: http.StatusText(resp.StatusCode), HTTPStatus: resp.StatusCode}
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	logging.LogRequest(ctx, req.Method, req.URL.String(), resp.StatusCode, respBody, attempts, time.Since(start), err)
+	if err != nil {
+		return nil, &auth.NetworkError{Op: "reading response body", Err: err}
+	}
+
+	return respBody, nil
+}
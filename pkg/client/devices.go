@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+// DeviceListRequest represents the JSON request body sent to the Vicohome API
+// when listing user devices. It specifies language and country preferences.
+type DeviceListRequest struct {
+	Language  string `json:"language"`  // Language code (e.g., "en" for English)
+	CountryNo string `json:"countryNo"` // Country code (e.g., "US" for United States)
+}
+
+// DeviceRequest represents the JSON request body sent to the Vicohome API
+// when fetching a specific device by serial number.
+type DeviceRequest struct {
+	SerialNumber string `json:"serialNumber"` // Unique identifier for the device
+	Language     string `json:"language"`     // Language code (e.g., "en" for English)
+	CountryNo    string `json:"countryNo"`    // Country code (e.g., "US" for United States)
+}
+
+// ListDevices retrieves all devices associated with the authenticated account.
+func (c *Client) ListDevices(ctx context.Context) ([]models.Device, error) {
+	req := DeviceListRequest{
+		Language:  c.Language,
+		CountryNo: c.CountryNo,
+	}
+
+	responseMap, err := c.call(ctx, "/device/listuserdevices", req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract device list
+	data, ok := responseMap["data"].(map[string]interface{})
+	if !ok {
+		return []models.Device{}, nil
+	}
+
+	deviceList, ok := data["list"].([]interface{})
+	if !ok {
+		return []models.Device{}, nil
+	}
+
+	// Transform devices to our simpler format
+	devices := make([]models.Device, 0, len(deviceList))
+	for _, item := range deviceList {
+		if deviceMap, ok := item.(map[string]interface{}); ok {
+			devices = append(devices, transformToDevice(deviceMap))
+		}
+	}
+
+	return devices, nil
+}
+
+// GetDevice retrieves a single device by its serial number.
+func (c *Client) GetDevice(ctx context.Context, serialNumber string) (models.Device, error) {
+	req := DeviceRequest{
+		SerialNumber: serialNumber,
+		Language:     c.Language,
+		CountryNo:    c.CountryNo,
+	}
+
+	responseMap, err := c.call(ctx, "/device/selectsingledevice", req)
+	if err != nil {
+		return models.Device{}, err
+	}
+
+	// Extract device data
+	data, ok := responseMap["data"].(map[string]interface{})
+	if !ok {
+		return models.Device{}, &auth.NotFoundError{Kind: "device", ID: serialNumber}
+	}
+
+	return transformToDevice(data), nil
+}
+
+// transformToDevice converts a map of device data from the API response into a Device struct.
+// It safely extracts and type-converts the various device properties from the dynamic map
+// into the strongly-typed Device structure. Missing fields in the map will result in
+// zero values in the returned Device structure.
+func transformToDevice(deviceMap map[string]interface{}) models.Device {
+	device := models.Device{}
+
+	// Extract string fields
+	if val, ok := deviceMap["serialNumber"].(string); ok {
+		device.SerialNumber = val
+	}
+	if val, ok := deviceMap["modelNo"].(string); ok {
+		device.ModelNo = val
+	}
+	if val, ok := deviceMap["deviceName"].(string); ok {
+		device.DeviceName = val
+	}
+	if val, ok := deviceMap["networkName"].(string); ok {
+		device.NetworkName = val
+	}
+	if val, ok := deviceMap["ip"].(string); ok {
+		device.IP = val
+	}
+	if val, ok := deviceMap["locationName"].(string); ok {
+		device.LocationName = val
+	}
+	if val, ok := deviceMap["macAddress"].(string); ok {
+		device.MacAddress = val
+	}
+
+	// Extract numeric fields
+	if val, ok := deviceMap["batteryLevel"].(float64); ok {
+		device.BatteryLevel = int(val)
+	}
+	if val, ok := deviceMap["signalStrength"].(float64); ok {
+		device.SignalStrength = int(val)
+	}
+	if val, ok := deviceMap["wifiChannel"].(float64); ok {
+		device.WifiChannel = int(val)
+	}
+	if val, ok := deviceMap["isCharging"].(float64); ok {
+		device.IsCharging = int(val)
+	}
+	if val, ok := deviceMap["chargingMode"].(float64); ok {
+		device.ChargingMode = int(val)
+	}
+
+	return device
+}
@@ -0,0 +1,434 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/logging"
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+// EventListRequest represents the JSON request body sent to the Vicohome API
+// when fetching events within a specific time range. From and To select a page
+// of the matching events by offset.
+type EventListRequest struct {
+	StartTimestamp string `json:"startTimestamp"` // Start time in Unix timestamp format
+	EndTimestamp   string `json:"endTimestamp"`   // End time in Unix timestamp format
+	From           int    `json:"from"`           // Offset of the first event in the page
+	To             int    `json:"to"`             // Offset one past the last event in the page
+	Language       string `json:"language"`       // Language code (e.g., "en" for English)
+	CountryNo      string `json:"countryNo"`      // Country code (e.g., "US" for United States)
+}
+
+// EventRequest represents the JSON request body sent to the Vicohome API
+// when fetching a specific event by its trace ID.
+type EventRequest struct {
+	TraceID   string `json:"traceId"`   // Unique identifier for the event
+	Language  string `json:"language"`  // Language code (e.g., "en" for English)
+	CountryNo string `json:"countryNo"` // Country code (e.g., "US" for United States)
+}
+
+// ListEvents retrieves all events recorded between start and end.
+// The range is split into windows of at most EventWindow, and each window is paged
+// through PageSize events at a time until it is exhausted, so long ranges are not
+// truncated by the API's per-request limit. When Concurrency is greater than one,
+// up to that many windows are fetched in parallel. Events are deduplicated by trace
+// ID and returned newest first. Fetching stops when ctx is done.
+func (c *Client) ListEvents(ctx context.Context, start, end time.Time) ([]models.Event, error) {
+	events := []models.Event{}
+	err := c.StreamEvents(ctx, start, end, func(event models.Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Timestamps use a fixed-width layout, so string order is chronological order
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp > events[j].Timestamp
+	})
+
+	return events, nil
+}
+
+// StreamEvents retrieves the events recorded between start and end like ListEvents,
+// but passes each event to fn as soon as its window has been fetched instead of
+// collecting them. Windows are delivered newest first, and the events within a
+// window newest first, so fn sees the same order as ListEvents returns. If fn
+// returns an error, fetching a window fails or ctx is done, requests in progress are
+// canceled, no further windows are fetched and the error is returned.
+//
+// Parameters:
+//   - ctx: Cancels fetching when done
+//   - start: The start of the time range
+//   - end: The end of the time range
+//   - fn: Called once for each event, deduplicated by trace ID
+//
+// Returns:
+//   - error: An error if fetching a window failed or fn returned an error
+func (c *Client) StreamEvents(ctx context.Context, start, end time.Time, fn func(models.Event) error) error {
+	// Cancel the requests of windows still being fetched when returning early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	windows := splitWindows(start, end, c.EventWindow)
+
+	workers := c.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(windows) {
+		workers = len(windows)
+	}
+
+	// Each window delivers its result on its own buffered channel, so workers never
+	// block and results can be consumed in window order as they complete.
+	type windowResult struct {
+		events []models.Event
+		pages  int
+		err    error
+	}
+	results := make([]chan windowResult, len(windows))
+	for idx := range results {
+		results[idx] = make(chan windowResult, 1)
+	}
+
+	// Hand windows to a bounded pool of workers until all are fetched or the
+	// caller stops consuming.
+	jobs := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(jobs)
+		for idx := range windows {
+			select {
+			case jobs <- idx:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		go func() {
+			for idx := range jobs {
+				w := windows[idx]
+				events, pages, err := c.listEventWindow(ctx, w.start, w.end)
+				results[idx] <- windowResult{events, pages, err}
+			}
+		}()
+	}
+
+	// Deliver the windows in order, dropping events that appear in more than one window
+	seen := make(map[string]bool)
+	count, pages := 0, 0
+	for idx := range windows {
+		result := <-results[idx]
+		pages += result.pages
+		if result.err != nil {
+			return result.err
+		}
+
+		sort.SliceStable(result.events, func(i, j int) bool {
+			return result.events[i].Timestamp > result.events[j].Timestamp
+		})
+		for _, event := range result.events {
+			if event.TraceID != "" && seen[event.TraceID] {
+				continue
+			}
+			seen[event.TraceID] = true
+			count++
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	logging.Logger().DebugContext(ctx, "fetched events", "events", count, "pages", pages, "windows", len(windows), "workers", workers)
+
+	return nil
+}
+
+// listEventWindow pages through the events in a single time window.
+// Each request asks for PageSize events, and the next page starts after the events
+// the API actually returned, so a server that caps pages at a smaller size does not
+// cause events to be skipped. Paging stops at the data.total the API reports, or at
+// the first empty page when it reports none. It returns the events and the number
+// of pages requested.
+func (c *Client) listEventWindow(ctx context.Context, start, end time.Time) ([]models.Event, int, error) {
+	pageSize := c.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	seen := make(map[string]bool)
+	var events []models.Event
+	pages, from := 0, 0
+	complete := false
+	for !complete && pages < maxPagesPerWindow {
+		req := EventListRequest{
+			StartTimestamp: fmt.Sprintf("%d", start.Unix()),
+			EndTimestamp:   fmt.Sprintf("%d", end.Unix()),
+			From:           from,
+			To:             from + pageSize,
+			Language:       c.Language,
+			CountryNo:      c.CountryNo,
+		}
+
+		responseMap, err := c.call(ctx, "/library/newselectlibrary", req)
+		if err != nil {
+			return nil, pages, err
+		}
+		pages++
+
+		page := c.eventsFromResponse(responseMap)
+		added := 0
+		for _, event := range page {
+			if event.TraceID != "" && seen[event.TraceID] {
+				continue
+			}
+			seen[event.TraceID] = true
+			events = append(events, event)
+			added++
+		}
+		from += len(page)
+
+		// A page without any new events means the window is exhausted, or that the
+		// API ignored the offsets and returned everything at once.
+		complete = added == 0
+		if total, ok := totalFromResponse(responseMap); ok && from >= total {
+			complete = true
+		}
+	}
+
+	if !complete {
+		logging.Logger().WarnContext(ctx, "event window reached the page limit, results may be incomplete",
+			"start", start.Format(time.RFC3339), "end", end.Format(time.RFC3339), "pages", pages, "events", len(events))
+	}
+
+	return events, pages, nil
+}
+
+// totalFromResponse returns the data.total count of an event list response, if the
+// API reported one.
+func totalFromResponse(responseMap map[string]interface{}) (int, bool) {
+	data, ok := responseMap["data"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	total, ok := data["total"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(total), true
+}
+
+// eventsFromResponse extracts and transforms the data.list array of an event list response.
+func (c *Client) eventsFromResponse(responseMap map[string]interface{}) []models.Event {
+	// Extract the event list
+	data, ok := responseMap["data"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	eventList, ok := data["list"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	// Transform events to our simpler format
+	events := make([]models.Event, 0, len(eventList))
+	for _, item := range eventList {
+		if eventMap, ok := item.(map[string]interface{}); ok {
+			events = append(events, c.transformRawEvent(eventMap))
+		}
+	}
+
+	return events
+}
+
+// timeWindow is a closed interval of time.
+type timeWindow struct {
+	start, end time.Time
+}
+
+// splitWindows divides [start, end] into consecutive windows no longer than size,
+// ordered newest first to match the API's ordering of events. A non-positive size
+// yields a single window covering the whole range.
+func splitWindows(start, end time.Time, size time.Duration) []timeWindow {
+	if size <= 0 || !end.After(start) {
+		return []timeWindow{{start, end}}
+	}
+
+	var windows []timeWindow
+	for windowEnd := end; windowEnd.After(start); windowEnd = windowEnd.Add(-size) {
+		windowStart := windowEnd.Add(-size)
+		if windowStart.Before(start) {
+			windowStart = start
+		}
+		windows = append(windows, timeWindow{windowStart, windowEnd})
+	}
+	return windows
+}
+
+// GetEvent retrieves a single event by its trace ID.
+func (c *Client) GetEvent(ctx context.Context, traceID string) (models.Event, error) {
+	req := EventRequest{
+		TraceID:   traceID,
+		Language:  c.Language,
+		CountryNo: c.CountryNo,
+	}
+
+	responseMap, err := c.call(ctx, "/library/newselectsinglelibrary", req)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	// Extract event data
+	data, ok := responseMap["data"].(map[string]interface{})
+	if !ok {
+		return models.Event{}, &auth.NotFoundError{Kind: "event", ID: traceID}
+	}
+
+	// First check if data has the traceId field, which indicates it's an event
+	if _, hasTraceID := data["traceId"].(string); hasTraceID {
+		return c.transformRawEvent(data), nil
+	}
+
+	// If we didn't find the event directly in data, try data.event as a fallback
+	event, ok := data["event"].(map[string]interface{})
+	if !ok {
+		return models.Event{}, &auth.NotFoundError{Kind: "event", ID: traceID}
+	}
+
+	return c.transformRawEvent(event), nil
+}
+
+// transformRawEvent converts a map of event data from the API response into an Event struct.
+// It safely extracts and type-converts the various event properties from the dynamic map
+// into the strongly-typed Event structure. It handles special processing for detections,
+// timestamps, and keyshots. Default values are provided for missing or unidentified fields.
+// Timestamps are formatted in the client's Location.
+func (c *Client) transformRawEvent(eventMap map[string]interface{}) models.Event {
+	event := models.Event{}
+
+	// Extract string fields
+	if val, ok := eventMap["traceId"].(string); ok {
+		event.TraceID = val
+	}
+
+	// Fix: Handle timestamp as a number
+	if val, ok := eventMap["timestamp"].(float64); ok {
+		// Convert Unix timestamp to human-readable format
+		loc := c.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		t := time.Unix(int64(val), 0).In(loc)
+		event.Timestamp = t.Format("2006-01-02 15:04:05")
+	} else if val, ok := eventMap["timestamp"].(string); ok {
+		event.Timestamp = val
+	}
+
+	if val, ok := eventMap["deviceName"].(string); ok {
+		event.DeviceName = val
+	}
+	if val, ok := eventMap["serialNumber"].(string); ok {
+		event.SerialNumber = val
+	}
+	if val, ok := eventMap["adminName"].(string); ok {
+		event.AdminName = val
+	}
+
+	// Fix: Handle period as a number
+	if val, ok := eventMap["period"].(float64); ok {
+		event.Period = fmt.Sprintf("%.2fs", val)
+	} else if val, ok := eventMap["period"].(string); ok {
+		event.Period = val
+	}
+
+	if val, ok := eventMap["imageUrl"].(string); ok {
+		event.ImageURL = val
+	}
+	if val, ok := eventMap["videoUrl"].(string); ok {
+		event.VideoURL = val
+	}
+
+	// Collect every identified subject, highest confidence first
+	event.Detections = []models.Detection{}
+	if subcategoryInfoList, ok := eventMap["subcategoryInfoList"].([]interface{}); ok {
+		for _, info := range subcategoryInfoList {
+			if infoMap, ok := info.(map[string]interface{}); ok {
+				detection := models.Detection{}
+				if objectType, ok := infoMap["objectType"].(string); ok {
+					detection.ObjectType = models.NormalizeCategory(objectType)
+				}
+				if name, ok := infoMap["objectName"].(string); ok {
+					detection.Name = name
+				}
+				if latin, ok := infoMap["birdStdName"].(string); ok {
+					detection.LatinName = latin
+				}
+				if confidence, ok := infoMap["confidence"].(float64); ok {
+					detection.Confidence = confidence
+				}
+				event.Detections = append(event.Detections, detection)
+			}
+		}
+	}
+	sort.SliceStable(event.Detections, func(i, j int) bool {
+		return event.Detections[i].Confidence > event.Detections[j].Confidence
+	})
+
+	// The top bird detection fills the flat bird fields
+	event.BirdName = "Unidentified"
+	for _, detection := range event.Detections {
+		if detection.ObjectType == models.CategoryBird {
+			if detection.Name != "" {
+				event.BirdName = detection.Name
+			}
+			event.BirdLatin = detection.LatinName
+			event.BirdConfidence = detection.Confidence
+			break
+		}
+	}
+
+	// Handle the keyshots field separately
+	event.Keyshots = []models.Keyshot{}
+	if keyshots, ok := eventMap["keyshots"].([]interface{}); ok {
+		for _, ks := range keyshots {
+			if ksMap, ok := ks.(map[string]interface{}); ok {
+				keyshot := models.Keyshot{}
+				if url, ok := ksMap["imageUrl"].(string); ok {
+					keyshot.ImageURL = url
+					// Extract the first keyshot URL for the flat structure
+					if event.KeyShotURL == "" {
+						event.KeyShotURL = url
+					}
+				}
+				if msg, ok := ksMap["message"].(string); ok {
+					keyshot.Message = msg
+				}
+				if cat, ok := ksMap["objectCategory"].(string); ok {
+					keyshot.ObjectCategory = cat
+				}
+				if sub, ok := ksMap["subCategoryName"].(string); ok {
+					keyshot.SubCategoryName = sub
+				}
+				event.Keyshots = append(event.Keyshots, keyshot)
+			}
+		}
+	}
+
+	// The event's category is that of its top detection, falling back to the first keyshot
+	if len(event.Detections) > 0 {
+		event.Category = event.Detections[0].ObjectType
+	} else if len(event.Keyshots) > 0 {
+		event.Category = models.NormalizeCategory(event.Keyshots[0].ObjectCategory)
+	}
+
+	return event
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/dydx/vico-cli/pkg/retry"
+)
+
+func TestStreamEventsCancelsWorkersOnError(t *testing.T) {
+	end := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-3 * 24 * time.Hour)
+	newest := fmt.Sprint(end.Add(-24 * time.Hour).Unix())
+
+	canceled := make(chan struct{}, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req EventListRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.StartTimestamp == newest {
+			io.WriteString(w, `{"result":-1,"msg":"boom"}`)
+			return
+		}
+		// Older windows are slow; they must be canceled once the newest one fails
+		<-r.Context().Done()
+		canceled <- struct{}{}
+	}))
+	defer server.Close()
+	defer server.CloseClientConnections()
+
+	c := New(StaticToken("token"))
+	c.BaseURL = server.URL
+	c.Concurrency = 3
+	c.Retry = retry.Policy{}
+
+	err := c.StreamEvents(context.Background(), start, end, func(models.Event) error { return nil })
+	var apiErr *auth.APIError
+	if !errors.As(err, &apiErr) || apiErr.Msg != "boom" {
+		t.Fatalf("StreamEvents error = %v, want the API error of the newest window", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-canceled:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%d of 2 requests for older windows were canceled after StreamEvents returned", i)
+		}
+	}
+}
+
+func TestListEventWindowPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int  // most events the server returns per page
+		total     bool // whether the server reports data.total
+		offsets   bool // whether the server honors from/to
+		wantPages int
+	}{
+		{name: "total reported", limit: 100, total: true, offsets: true, wantPages: 3},
+		{name: "no total", limit: 100, offsets: true, wantPages: 4},
+		{name: "server caps pages", limit: 3, total: true, offsets: true, wantPages: 4},
+		{name: "server caps pages without total", limit: 3, offsets: true, wantPages: 5},
+		{name: "offsets ignored", limit: 100, wantPages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const count = 10
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req EventListRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				from, to := 0, count
+				if tt.offsets {
+					from, to = min(req.From, count), min(req.To, count)
+				}
+				to = min(to, from+tt.limit)
+
+				list := []map[string]interface{}{}
+				for i := from; i < to; i++ {
+					list = append(list, map[string]interface{}{"traceId": fmt.Sprint("trace-", i)})
+				}
+				data := map[string]interface{}{"list": list}
+				if tt.total {
+					data["total"] = count
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"result": 0, "data": data})
+			}))
+			defer server.Close()
+
+			c := New(StaticToken("token"))
+			c.BaseURL = server.URL
+			c.PageSize = 4
+			c.Retry = retry.Policy{}
+
+			end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+			events, pages, err := c.listEventWindow(context.Background(), end.Add(-time.Hour), end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != count {
+				t.Errorf("got %d events, want %d", len(events), count)
+			}
+			if pages != tt.wantPages {
+				t.Errorf("requested %d pages, want %d", pages, tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+// Package config reads and writes the CLI's configuration file.
+//
+// The configuration file, ~/.vicohome/config.yaml by default, holds named profiles.
+// Each profile groups the settings for one account: the account's email and where
+// to find its password, the API region, and display preferences. One profile is
+// marked as current and used unless another is selected:
+//
+//	current-profile: home
+//	profiles:
+//	  home:
+//	    email: me@example.com
+//	    password-env: VICOHOME_HOME_PASSWORD
+//	    region: us
+//	    timezone: America/New_York
+//	  office:
+//	    email: office@example.com
+//	    password-env: VICOHOME_OFFICE_PASSWORD
+//	    region: eu
+//	    format: json
+//
+// The file is YAML. Unknown settings are rejected, so that a misspelled key is
+// reported rather than silently ignored.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/client"
+	"github.com/dydx/vico-cli/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the profile used when none is selected and the file has no current profile.
+const DefaultProfile = "default"
+
+// CurrentProfileKey is the key of the top-level setting that names the current profile.
+const CurrentProfileKey = "current-profile"
+
+// Keys lists the settings of a profile, in the order they are written.
+var Keys = []string{"email", "password-env", "region", "api-url", "format", "timezone", "language", "country"}
+
+// Profile holds the settings for one account. Empty settings are not set.
+// The fields are in the order of Keys, which is the order they are written in.
+type Profile struct {
+	Email       string `yaml:"email,omitempty"`        // Account email address
+	PasswordEnv string `yaml:"password-env,omitempty"` // Environment variable holding the account password
+	Region      string `yaml:"region,omitempty"`       // API region (us or eu)
+	APIURL      string `yaml:"api-url,omitempty"`      // Custom API base URL, overrides Region
+	Format      string `yaml:"format,omitempty"`       // Default output format
+	Timezone    string `yaml:"timezone,omitempty"`     // IANA time zone for event times, e.g. Europe/Berlin
+	Language    string `yaml:"language,omitempty"`     // Language code sent with requests, e.g. en
+	Country     string `yaml:"country,omitempty"`      // Country code sent with requests, e.g. US
+}
+
+// Config is the contents of a configuration file.
+type Config struct {
+	CurrentProfile string              `yaml:"current-profile,omitempty"` // Profile used when none is selected
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`        // Profiles by name
+}
+
+// DefaultPath returns the location of the configuration file, ~/.vicohome/config.yaml.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".vicohome", "config.yaml"), nil
+}
+
+// Load reads the configuration file at path. A missing file yields an empty configuration.
+//
+// Parameters:
+//   - path: The location of the configuration file
+//
+// Returns:
+//   - *Config: The configuration
+//   - error: An error if the file cannot be read or is malformed
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{Profiles: make(map[string]*Profile)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration to path, creating its directory if needed.
+// The file is only readable by the current user.
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	data, err := c.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+// ProfileName returns the name of the profile to use: selected if it is not empty,
+// otherwise the current profile, otherwise DefaultProfile.
+func (c *Config) ProfileName(selected string) string {
+	if selected != "" {
+		return selected
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// Profile returns the named profile, creating an empty one if it does not exist.
+func (c *Config) Profile(name string) *Profile {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		profile = &Profile{}
+		c.Profiles[name] = profile
+	}
+	return profile
+}
+
+// ProfileNames returns the names of all profiles in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value of a profile setting.
+//
+// Parameters:
+//   - key: One of Keys
+//
+// Returns:
+//   - string: The value, or "" if it is not set
+//   - error: An error if the key is unknown
+func (p *Profile) Get(key string) (string, error) {
+	field, err := p.field(key)
+	if err != nil {
+		return "", err
+	}
+	return *field, nil
+}
+
+// Set changes a profile setting. An empty value unsets it.
+//
+// Parameters:
+//   - key: One of Keys
+//   - value: The new value
+//
+// Returns:
+//   - error: An error if the key is unknown or the value is invalid for the key
+func (p *Profile) Set(key, value string) error {
+	field, err := p.field(key)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSetting(key, value); err != nil {
+		return err
+	}
+
+	*field = value
+	return nil
+}
+
+// Validate checks the profile's settings as Set does.
+func (p *Profile) Validate() error {
+	for _, key := range Keys {
+		value, err := p.Get(key)
+		if err != nil {
+			return err
+		}
+		if err := checkSetting(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSetting returns an error if value is not valid for the setting key, so that
+// a mistake is reported when the setting is made rather than by a later command.
+// Regions and API URLs must be usable by the client, formats must be registered
+// output formats, and time zones must be known. Empty values are always valid.
+func checkSetting(key, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch key {
+	case "region":
+		if _, err := client.ResolveBaseURL(value, ""); err != nil {
+			return err
+		}
+	case "api-url":
+		if _, err := client.ResolveBaseURL("", value); err != nil {
+			return err
+		}
+	case "format":
+		// A template may follow the format name, as in "jsonpath={.traceId}"
+		name, _, _ := strings.Cut(value, "=")
+		formats := output.Formats()
+		for _, format := range formats {
+			if strings.EqualFold(name, format) {
+				return nil
+			}
+		}
+		return fmt.Errorf("unsupported output format %q (supported: %s)", name, strings.Join(formats, ", "))
+	case "timezone":
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", value, err)
+		}
+	}
+	return nil
+}
+
+// Location returns the profile's time zone, or time.Local if none is set.
+func (p *Profile) Location() (*time.Location, error) {
+	if p.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", p.Timezone, err)
+	}
+	return loc, nil
+}
+
+// field returns a pointer to the setting with the given key.
+func (p *Profile) field(key string) (*string, error) {
+	switch key {
+	case "email":
+		return &p.Email, nil
+	case "password-env":
+		return &p.PasswordEnv, nil
+	case "region":
+		return &p.Region, nil
+	case "api-url":
+		return &p.APIURL, nil
+	case "format":
+		return &p.Format, nil
+	case "timezone":
+		return &p.Timezone, nil
+	case "language":
+		return &p.Language, nil
+	case "country":
+		return &p.Country, nil
+	}
+	return nil, fmt.Errorf("unknown setting %q (supported: %s)", key, strings.Join(Keys, ", "))
+}
+
+// Parse parses the contents of a configuration file. Profiles without settings are
+// empty profiles, and every profile's settings must be valid, see Profile.Set.
+//
+// Parameters:
+//   - data: The YAML document
+//
+// Returns:
+//   - *Config: The configuration
+//   - error: An error if the document is not valid YAML, has unknown settings or
+//     an invalid value, such as an unknown region or time zone
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		// An empty document leaves the configuration empty
+		return nil, err
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*Profile)
+	}
+	for name, profile := range cfg.Profiles {
+		if profile == nil {
+			cfg.Profiles[name] = &Profile{}
+			continue
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	return cfg, nil
+}
+
+// Marshal returns the configuration as a YAML document read back by Parse.
+// Profiles are written in sorted order, and unset settings are left out.
+func (c *Config) Marshal() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("# vico-cli configuration\n")
+
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return nil, fmt.Errorf("error encoding config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("error encoding config: %w", err)
+	}
+	return b.Bytes(), nil
+}
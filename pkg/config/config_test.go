@@ -0,0 +1,285 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *Config
+		wantErr string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: &Config{Profiles: map[string]*Profile{}},
+		},
+		{
+			name: "comments only",
+			data: "# nothing here\n",
+			want: &Config{Profiles: map[string]*Profile{}},
+		},
+		{
+			name: "profiles",
+			data: `current-profile: home
+profiles:
+  home:
+    email: me@example.com # the main account
+    password-env: VICOHOME_HOME_PASSWORD
+    timezone: America/New_York
+  office:
+    email: 'office@example.com'
+    region: "eu"
+    api-url: http://127.0.0.1:8080
+`,
+			want: &Config{
+				CurrentProfile: "home",
+				Profiles: map[string]*Profile{
+					"home":   {Email: "me@example.com", PasswordEnv: "VICOHOME_HOME_PASSWORD", Timezone: "America/New_York"},
+					"office": {Email: "office@example.com", Region: "eu", APIURL: "http://127.0.0.1:8080"},
+				},
+			},
+		},
+		{
+			name: "document marker",
+			data: "---\ncurrent-profile: home\n",
+			want: &Config{CurrentProfile: "home", Profiles: map[string]*Profile{}},
+		},
+		{
+			name: "empty profiles mapping",
+			data: "profiles: {}\n",
+			want: &Config{Profiles: map[string]*Profile{}},
+		},
+		{
+			name: "flow style",
+			data: "profiles: {home: {email: me@example.com, format: json}}\n",
+			want: &Config{Profiles: map[string]*Profile{"home": {Email: "me@example.com", Format: "json"}}},
+		},
+		{
+			name: "anchors",
+			data: `profiles:
+  home: &shared
+    region: eu
+    country: DE
+  office: *shared
+`,
+			want: &Config{Profiles: map[string]*Profile{
+				"home":   {Region: "eu", Country: "DE"},
+				"office": {Region: "eu", Country: "DE"},
+			}},
+		},
+		{
+			name: "profile without settings",
+			data: "profiles:\n  home:\n",
+			want: &Config{Profiles: map[string]*Profile{"home": {}}},
+		},
+		{
+			name: "numeric value",
+			data: "profiles:\n  home:\n    country: 49\n",
+			want: &Config{Profiles: map[string]*Profile{"home": {Country: "49"}}},
+		},
+		{
+			name:    "unknown top-level setting",
+			data:    "colour: blue\n",
+			wantErr: "colour",
+		},
+		{
+			name:    "unknown profile setting",
+			data:    "profiles:\n  home:\n    emial: me@example.com\n",
+			wantErr: "emial",
+		},
+		{
+			name:    "invalid timezone",
+			data:    "profiles:\n  home:\n    timezone: Mars/Olympus\n",
+			wantErr: `profile "home": invalid timezone`,
+		},
+		{
+			name:    "unknown region",
+			data:    "profiles:\n  home:\n    region: xx\n",
+			wantErr: `profile "home": unknown region "xx"`,
+		},
+		{
+			name:    "relative API URL",
+			data:    "profiles:\n  home:\n    api-url: localhost:8080\n",
+			wantErr: `profile "home": invalid API URL`,
+		},
+		{
+			name:    "unknown format",
+			data:    "profiles:\n  home:\n    format: bogus\n",
+			wantErr: `profile "home": unsupported output format "bogus"`,
+		},
+		{
+			name:    "profiles as a list",
+			data:    "profiles:\n  - home\n",
+			wantErr: "cannot unmarshal",
+		},
+		{
+			name:    "malformed",
+			data:    "current-profile: [home\n",
+			wantErr: "yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse error = %v", err)
+			}
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	cfg := &Config{
+		CurrentProfile: "home",
+		Profiles: map[string]*Profile{
+			"home": {Email: "me@example.com", Region: "us", Timezone: "Europe/Berlin"},
+			// Values that YAML would otherwise read as other types or syntax
+			"odd: name": {PasswordEnv: "# not a comment", Country: "49", Language: "no", Format: "template={{.TraceID}}: {{.BirdName}}"},
+			"empty":     {},
+		},
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# vico-cli configuration\n") {
+		t.Errorf("Marshal output does not start with the header comment:\n%s", data)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse of Marshal output failed: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(parsed, cfg) {
+		t.Errorf("round trip = %+v, want %+v\n%s", parsed, cfg, data)
+	}
+}
+
+func TestMarshalKeyOrder(t *testing.T) {
+	cfg := &Config{Profiles: map[string]*Profile{"home": {Country: "US", Email: "me@example.com", Region: "us"}}}
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# vico-cli configuration
+profiles:
+  home:
+    email: me@example.com
+    region: us
+    country: US
+`
+	if string(data) != want {
+		t.Errorf("Marshal =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.yaml")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of a missing file failed: %v", err)
+	}
+	if len(cfg.Profiles) != 0 || cfg.CurrentProfile != "" {
+		t.Fatalf("Load of a missing file = %+v, want an empty configuration", cfg)
+	}
+
+	if err := cfg.Profile("home").Set("email", "me@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.CurrentProfile = "home"
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config file mode = %o, want 600", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email, _ := loaded.Profile(loaded.ProfileName("")).Get("email"); email != "me@example.com" {
+		t.Errorf("email of the current profile = %q, want me@example.com", email)
+	}
+}
+
+func TestProfileSet(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr string
+	}{
+		{key: "region", value: "eu"},
+		{key: "region", value: "US"},
+		{key: "region", value: "xx", wantErr: `unknown region "xx"`},
+		{key: "api-url", value: "http://127.0.0.1:8080"},
+		{key: "api-url", value: "127.0.0.1:8080", wantErr: "invalid API URL"},
+		{key: "api-url", value: "/v1", wantErr: "invalid API URL"},
+		{key: "format", value: "json"},
+		{key: "format", value: "CSV"},
+		{key: "format", value: "jsonpath={.traceId}"},
+		{key: "format", value: "bogus", wantErr: `unsupported output format "bogus"`},
+		{key: "format", value: "yaml={.traceId}", wantErr: `unsupported output format "yaml"`},
+		{key: "timezone", value: "Europe/Berlin"},
+		{key: "timezone", value: "Nowhere/Special", wantErr: "invalid timezone"},
+		{key: "email", value: "anything goes"},
+		{key: "colour", value: "blue", wantErr: `unknown setting "colour"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			p := &Profile{}
+			err := p.Set(tt.key, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Set error = %v, want an error containing %q", err, tt.wantErr)
+				}
+				if *p != (Profile{}) {
+					t.Errorf("rejected value was stored: %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set error = %v", err)
+			}
+			if got, _ := p.Get(tt.key); got != tt.value {
+				t.Errorf("Get = %q, want %q", got, tt.value)
+			}
+		})
+	}
+
+	// An empty value unsets any setting, without validation
+	p := &Profile{Region: "eu", Format: "json"}
+	for _, key := range Keys {
+		if err := p.Set(key, ""); err != nil {
+			t.Errorf("unsetting %s failed: %v", key, err)
+		}
+	}
+	if *p != (Profile{}) {
+		t.Errorf("profile after unsetting every key = %+v, want it empty", p)
+	}
+}
@@ -0,0 +1,472 @@
+// Package download saves the media attached to Vicohome events to disk.
+//
+// Files are named from a template such as "{date}/{device}/{bird}_{traceId}_{kind}{ext}".
+// Files that already exist are skipped, and interrupted downloads are resumed from
+// the partial ".part" file left behind by the previous attempt, provided the server
+// supports range requests. HLS videos are not resumed and are fetched again from
+// the first segment.
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/logging"
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/dydx/vico-cli/pkg/ratelimit"
+	"github.com/dydx/vico-cli/pkg/retry"
+)
+
+// Media kinds that can be downloaded for an event.
+const (
+	KindImage   = "image"   // Gallery image (Event.ImageURL)
+	KindKeyshot = "keyshot" // Detection keyshot (Event.KeyShotURL)
+	KindVideo   = "video"   // Video clip (Event.VideoURL)
+)
+
+// AllKinds lists every media kind in download order.
+var AllKinds = []string{KindImage, KindKeyshot, KindVideo}
+
+// DefaultTemplate is the filename template used when none is configured.
+const DefaultTemplate = "{date}/{device}/{bird}_{traceId}_{kind}{ext}"
+
+// ResponseHeaderTimeout bounds how long a Downloader created with New waits for a
+// server to start responding. Transfers themselves are not limited, since large
+// videos can take a long time; cancel the context to abandon them.
+const ResponseHeaderTimeout = 30 * time.Second
+
+// Result describes the outcome of downloading one media file.
+type Result struct {
+	Kind    string // Media kind (image, keyshot or video)
+	URL     string // Source URL
+	Path    string // Destination file path
+	Skipped bool   // True if the file already existed
+	Bytes   int64  // Bytes written during this download
+}
+
+// Downloader saves event media into a directory.
+type Downloader struct {
+	Dir        string             // Root directory for downloaded files
+	Template   string             // Filename template, relative to Dir
+	Kinds      []string           // Media kinds to download
+	HTTPClient *http.Client       // HTTP client used for downloads
+	Retry      retry.Policy       // How requests that fail for transient reasons are retried
+	Limiter    *ratelimit.Limiter // Limits the request rate; nil means unlimited
+}
+
+// New creates a Downloader that saves all media kinds into dir using the given
+// filename template. An empty template selects DefaultTemplate.
+func New(dir, template string) *Downloader {
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = ResponseHeaderTimeout
+
+	return &Downloader{
+		Dir:        dir,
+		Template:   template,
+		Kinds:      AllKinds,
+		HTTPClient: &http.Client{Transport: transport},
+		Retry:      retry.DefaultPolicy(),
+	}
+}
+
+// Validate checks that the media kinds are known and that the template can
+// produce distinct filenames for them.
+func (d *Downloader) Validate() error {
+	for _, kind := range d.Kinds {
+		if !isKnownKind(kind) {
+			return fmt.Errorf("unknown media kind %q (supported: %s)", kind, strings.Join(AllKinds, ", "))
+		}
+	}
+	if len(d.Kinds) > 1 && !strings.Contains(d.Template, "{kind}") {
+		return fmt.Errorf("filename template must contain {kind} when downloading more than one media kind")
+	}
+	return nil
+}
+
+// DownloadEvent downloads the selected media kinds of an event.
+// Media kinds without a URL on the event are ignored. When ctx is done, the download
+// in progress is abandoned and its partial file kept, so that it can be resumed later.
+//
+// Parameters:
+//   - ctx: Cancels the downloads when done
+//   - event: The event whose media should be downloaded
+//
+// Returns:
+//   - []Result: One result per downloaded or skipped file
+//   - error: The first error encountered; earlier results are still returned
+func (d *Downloader) DownloadEvent(ctx context.Context, event models.Event) ([]Result, error) {
+	var results []Result
+	for _, kind := range d.Kinds {
+		src := mediaURL(event, kind)
+		if src == "" {
+			continue
+		}
+
+		ext := extension(src)
+		if isPlaylist(src) {
+			// HLS playlists are saved as the concatenated transport stream
+			ext = ".ts"
+		}
+
+		dest := filepath.Join(d.Dir, filepath.FromSlash(d.filename(event, kind, ext)))
+		result := Result{Kind: kind, URL: src, Path: dest}
+
+		if info, err := os.Stat(dest); err == nil && info.Size() > 0 {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return results, fmt.Errorf("error creating directory: %w", err)
+		}
+
+		var n int64
+		var err error
+		if isPlaylist(src) {
+			n, err = d.fetchPlaylist(ctx, src, dest)
+		} else {
+			n, err = d.fetch(ctx, src, dest)
+		}
+		if err != nil {
+			return results, fmt.Errorf("error downloading %s for event %s: %w", kind, event.TraceID, err)
+		}
+
+		result.Bytes = n
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// filename expands the template for one media file of an event.
+func (d *Downloader) filename(event models.Event, kind, ext string) string {
+	date, clock := event.Timestamp, ""
+	if parts := strings.SplitN(event.Timestamp, " ", 2); len(parts) == 2 {
+		date, clock = parts[0], strings.ReplaceAll(parts[1], ":", "")
+	}
+
+	replacer := strings.NewReplacer(
+		"{date}", sanitize(date),
+		"{time}", sanitize(clock),
+		"{device}", sanitize(event.DeviceName),
+		"{serial}", sanitize(event.SerialNumber),
+		"{bird}", sanitize(event.BirdName),
+		"{traceId}", sanitize(event.TraceID),
+		"{kind}", kind,
+		"{ext}", ext,
+	)
+	return replacer.Replace(d.Template)
+}
+
+// fetch downloads a single URL to dest, resuming from dest.part if it exists.
+// The partial file is only appended to if the server's Content-Range starts where
+// it ends; otherwise it is discarded and the download starts over.
+// It returns the number of bytes written during this call.
+func (d *Downloader) fetch(ctx context.Context, mediaURL, dest string) (int64, error) {
+	partPath := dest + ".part"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := d.get(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, _, ok := contentRange(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return d.restart(ctx, resp, mediaURL, dest)
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if _, size, ok := contentRange(resp.Header.Get("Content-Range")); ok && size != offset {
+			return d.restart(ctx, resp, mediaURL, dest)
+		}
+		// The partial file already holds the whole body
+		return 0, os.Rename(partPath, dest)
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range request, so start over
+		flags |= os.O_TRUNC
+	default:
+		return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file: %w", err)
+	}
+
+	n, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, fmt.Errorf("error writing file: %w", err)
+	}
+
+	return n, os.Rename(partPath, dest)
+}
+
+// restart discards the partial file of dest after the server answered a range
+// request with a range that does not continue it, and downloads the URL from the start.
+func (d *Downloader) restart(ctx context.Context, resp *http.Response, mediaURL, dest string) (int64, error) {
+	logging.Logger().WarnContext(ctx, "server did not resume the download where it stopped, starting over",
+		"path", dest, "content_range", resp.Header.Get("Content-Range"))
+	resp.Body.Close()
+
+	if err := os.Remove(dest + ".part"); err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("error removing partial file: %w", err)
+	}
+	return d.fetch(ctx, mediaURL, dest)
+}
+
+// contentRange parses a Content-Range header such as "bytes 100-199/1000" or
+// "bytes */1000". It returns the first byte of the range, or -1 if the header
+// holds no range, and the complete size, or -1 if it is unknown.
+func contentRange(header string) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	rangeSpec, sizeSpec, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return 0, 0, false
+	}
+
+	size := int64(-1)
+	if sizeSpec != "*" {
+		n, err := strconv.ParseInt(sizeSpec, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false
+		}
+		size = n
+	}
+
+	if rangeSpec == "*" {
+		if size < 0 {
+			return 0, 0, false
+		}
+		return -1, size, true
+	}
+	first, last, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	if end, err := strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// fetchPlaylist downloads an HLS playlist and concatenates its segments into dest.
+// If the playlist is a master playlist, its first variant stream is used. Playlist
+// downloads are not resumed: an interrupted download starts over from the first
+// segment, since the partial file does not record which segments it holds.
+func (d *Downloader) fetchPlaylist(ctx context.Context, playlistURL, dest string) (int64, error) {
+	segments, err := d.playlistSegments(ctx, playlistURL, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	partPath := dest + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file: %w", err)
+	}
+
+	var total int64
+	for _, segment := range segments {
+		n, err := d.copyURL(ctx, segment, file)
+		total += n
+		if err != nil {
+			file.Close()
+			return total, err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return total, fmt.Errorf("error writing file: %w", err)
+	}
+	return total, os.Rename(partPath, dest)
+}
+
+// playlistSegments returns the absolute segment URLs of an HLS media playlist,
+// following master playlists up to depth levels deep.
+func (d *Downloader) playlistSegments(ctx context.Context, playlistURL string, depth int) ([]string, error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid playlist URL: %w", err)
+	}
+
+	var buf strings.Builder
+	if _, err := d.copyURL(ctx, playlistURL, &buf); err != nil {
+		return nil, err
+	}
+
+	var segments []string
+	isMaster := false
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF") {
+			isMaster = true
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ref, err := base.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid playlist entry %q: %w", line, err)
+		}
+		if isMaster {
+			if depth <= 0 {
+				return nil, fmt.Errorf("nested playlists are too deep")
+			}
+			return d.playlistSegments(ctx, ref.String(), depth-1)
+		}
+		segments = append(segments, ref.String())
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("playlist contains no segments")
+	}
+	return segments, nil
+}
+
+// copyURL writes the body of a GET request to w.
+func (d *Downloader) copyURL(ctx context.Context, rawURL string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := d.get(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("error reading response body: %w", err)
+	}
+	return n, nil
+}
+
+// get sends a GET request, waiting for the rate limiter before each attempt and
+// retrying attempts that fail for transient reasons, until the request's context is done.
+// The call is logged with logging.LogRequest.
+func (d *Downloader) get(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	attempts := 0
+	resp, err := d.Retry.Do(req.Context(), func() (*http.Response, error) {
+		attempts++
+		if err := d.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		return d.HTTPClient.Do(req)
+	})
+	if err != nil {
+		logging.LogRequest(req.Context(), req.Method, req.URL.String(), 0, nil, attempts, time.Since(start), err)
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	logging.LogRequest(req.Context(), req.Method, req.URL.String(), resp.StatusCode, nil, attempts, time.Since(start), nil)
+	return resp, nil
+}
+
+// mediaURL returns the URL of the given media kind for an event.
+func mediaURL(event models.Event, kind string) string {
+	switch kind {
+	case KindImage:
+		return event.ImageURL
+	case KindKeyshot:
+		return event.KeyShotURL
+	case KindVideo:
+		return event.VideoURL
+	default:
+		return ""
+	}
+}
+
+// isKnownKind reports whether kind is one of AllKinds.
+func isKnownKind(kind string) bool {
+	for _, known := range AllKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// extension returns the file extension of a URL's path, defaulting to ".jpg".
+func extension(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ".jpg"
+	}
+	if ext := path.Ext(u.Path); ext != "" {
+		return ext
+	}
+	return ".jpg"
+}
+
+// isPlaylist reports whether a URL points to an HLS playlist.
+func isPlaylist(rawURL string) bool {
+	return extension(rawURL) == ".m3u8"
+}
+
+// sanitize makes a template value safe to use as a single path element.
+func sanitize(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	if value == "." || value == ".." {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return '_'
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,291 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/dydx/vico-cli/pkg/retry"
+)
+
+// timeZero leaves out the Last-Modified header of served media.
+var timeZero time.Time
+
+// content is the body of every media file served in these tests.
+const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newTestDownloader returns a downloader into a temporary directory that does not retry.
+func newTestDownloader(t *testing.T, template string, kinds ...string) *Downloader {
+	t.Helper()
+	d := New(t.TempDir(), template)
+	d.Kinds = kinds
+	d.Retry = retry.Policy{}
+	return d
+}
+
+func TestFilename(t *testing.T) {
+	event := models.Event{
+		TraceID:      "trace-1",
+		Timestamp:    "2024-03-01 07:08:09",
+		DeviceName:   "Back/Yard",
+		SerialNumber: "SN:1",
+		BirdName:     "",
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{DefaultTemplate, "2024-03-01/Back_Yard/unknown_trace-1_image.jpg"},
+		{"{serial}/{time}_{kind}{ext}", "SN_1/070809_image.jpg"},
+		{"{device}-{bird}", "Back_Yard-unknown"},
+		{"fixed.jpg", "fixed.jpg"},
+	}
+	for _, tt := range tests {
+		d := New("", tt.template)
+		if got := d.filename(event, KindImage, ".jpg"); got != tt.want {
+			t.Errorf("filename(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if got := sanitize(".."); got != "_" {
+		t.Errorf("sanitize(..) = %q, want _", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := New("", DefaultTemplate).Validate(); err != nil {
+		t.Errorf("Validate of the default downloader = %v", err)
+	}
+	d := New("", "{traceId}{ext}")
+	if err := d.Validate(); err == nil {
+		t.Error("Validate accepted a template without {kind} for several kinds")
+	}
+	d.Kinds = []string{KindImage}
+	if err := d.Validate(); err != nil {
+		t.Errorf("Validate of a single kind without {kind} = %v", err)
+	}
+	d.Kinds = []string{"audio"}
+	if err := d.Validate(); err == nil {
+		t.Error("Validate accepted an unknown kind")
+	}
+}
+
+func TestDownloadEvent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeContent(w, r, "", timeZero, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(t, "{device}/{traceId}_{kind}{ext}", KindImage, KindKeyshot, KindVideo)
+	event := models.Event{
+		TraceID:    "trace-1",
+		DeviceName: "Feeder",
+		ImageURL:   server.URL + "/media/a.jpg?signature=secret",
+		KeyShotURL: server.URL + "/media/b.png",
+	}
+
+	results, err := d.DownloadEvent(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2 (no video URL)", len(results))
+	}
+	for i, want := range []string{"Feeder/trace-1_image.jpg", "Feeder/trace-1_keyshot.png"} {
+		if want := filepath.Join(d.Dir, filepath.FromSlash(want)); results[i].Path != want {
+			t.Errorf("result %d path = %q, want %q", i, results[i].Path, want)
+		}
+		if results[i].Skipped || results[i].Bytes != int64(len(content)) {
+			t.Errorf("result %d = %+v, want %d bytes downloaded", i, results[i], len(content))
+		}
+		checkFile(t, results[i].Path, content)
+	}
+
+	// Existing files are skipped without a request
+	requests = 0
+	results, err = d.DownloadEvent(context.Background(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if !result.Skipped || result.Bytes != 0 {
+			t.Errorf("second download of %s = %+v, want it skipped", result.Kind, result)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("second download sent %d requests, want 0", requests)
+	}
+}
+
+func TestDownloadEventResumes(t *testing.T) {
+	const offset = 10
+	tests := []struct {
+		name    string
+		partial string // content of the .part file left by an earlier attempt
+		handler http.HandlerFunc
+		want    int64 // bytes written by the resumed download
+	}{
+		{
+			name:    "range honored",
+			partial: content[:offset],
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") != fmt.Sprintf("bytes=%d-", offset) {
+					t.Errorf("Range = %q, want bytes=%d-", r.Header.Get("Range"), offset)
+				}
+				http.ServeContent(w, r, "", timeZero, strings.NewReader(content))
+			},
+			want: int64(len(content) - offset),
+		},
+		{
+			name:    "range ignored",
+			partial: content[:offset],
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(content))
+			},
+			want: int64(len(content)),
+		},
+		{
+			name:    "range changed",
+			partial: content[:offset],
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") == "" {
+					w.Write([]byte(content))
+					return
+				}
+				// A server answering with a range other than the one requested
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-4/%d", len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(content[:5]))
+			},
+			want: int64(len(content)),
+		},
+		{
+			name:    "range without Content-Range",
+			partial: content[:offset],
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Range") == "" {
+					w.Write([]byte(content))
+					return
+				}
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte(content[offset:]))
+			},
+			want: int64(len(content)),
+		},
+		{
+			name:    "already complete",
+			partial: content,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "", timeZero, strings.NewReader(content))
+			},
+			want: 0,
+		},
+		{
+			name:    "partial file longer than the media",
+			partial: content + "garbage",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "", timeZero, strings.NewReader(content))
+			},
+			want: int64(len(content)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			d := newTestDownloader(t, "{traceId}{ext}", KindImage)
+			dest := filepath.Join(d.Dir, "trace-1.jpg")
+			if err := os.WriteFile(dest+".part", []byte(tt.partial), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := d.DownloadEvent(context.Background(), models.Event{TraceID: "trace-1", ImageURL: server.URL + "/a.jpg"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results[0].Bytes != tt.want {
+				t.Errorf("wrote %d bytes, want %d", results[0].Bytes, tt.want)
+			}
+			checkFile(t, dest, content)
+			if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+				t.Errorf("partial file still exists: %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadPlaylist(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/master.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=800000\nvideo/media.m3u8\n")
+	})
+	mux.HandleFunc("/video/media.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXTINF:2.0,\nseg0.ts\n#EXTINF:2.0,\n/video/seg1.ts\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/video/seg0.ts", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "first-") })
+	mux.HandleFunc("/video/seg1.ts", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "second") })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	d := newTestDownloader(t, "{traceId}{ext}", KindVideo)
+	// A partial file of an earlier attempt is discarded, not resumed
+	if err := os.WriteFile(filepath.Join(d.Dir, "trace-1.ts.part"), []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := d.DownloadEvent(context.Background(), models.Event{TraceID: "trace-1", VideoURL: server.URL + "/master.m3u8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(d.Dir, "trace-1.ts"); results[0].Path != want {
+		t.Errorf("path = %q, want %q", results[0].Path, want)
+	}
+	checkFile(t, results[0].Path, "first-second")
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantStart int64
+		wantSize  int64
+		wantOK    bool
+	}{
+		{"bytes 10-35/36", 10, 36, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"bytes */36", -1, 36, true},
+		{"bytes */*", 0, 0, false},
+		{"bytes 10-5/36", 0, 0, false},
+		{"bytes 10/36", 0, 0, false},
+		{"items 0-9/36", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := contentRange(tt.header)
+		if start != tt.wantStart || size != tt.wantSize || ok != tt.wantOK {
+			t.Errorf("contentRange(%q) = %d, %d, %t, want %d, %d, %t", tt.header, start, size, ok, tt.wantStart, tt.wantSize, tt.wantOK)
+		}
+	}
+}
+
+// checkFile fails the test if the file at path does not hold want.
+func checkFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("%s holds %q, want %q", filepath.Base(path), data, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package logging holds the structured logger shared by the CLI and its packages.
+//
+// The client, auth, cache, retry and download packages log through Logger, which
+// discards everything until a program installs a logger with SetLogger, so that
+// importing the packages as a library adds no output. The CLI installs a logger
+// created by New from its --log-level and --log-format flags. Attributes whose keys
+// name a secret, such as "token" or "password", are redacted by loggers from New.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Log formats accepted by New.
+const (
+	FormatText = "text" // key=value pairs, for people
+	FormatJSON = "json" // One JSON object per line, for log aggregators
+)
+
+// Redacted replaces the values of secret attributes.
+const Redacted = "REDACTED"
+
+// discard is the logger used until SetLogger is called. Its level is above every
+// real level, so disabled records are not even formatted.
+var discard = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.Level(math.MaxInt32)}))
+
+var current atomic.Pointer[slog.Logger]
+
+// Logger returns the logger installed by SetLogger, or a logger that discards
+// everything if none has been installed.
+func Logger() *slog.Logger {
+	if logger := current.Load(); logger != nil {
+		return logger
+	}
+	return discard
+}
+
+// SetLogger installs the logger used by the packages of this module.
+// A nil logger restores the default of discarding everything.
+func SetLogger(logger *slog.Logger) {
+	current.Store(logger)
+}
+
+// New creates a logger writing records at or above level to w in the given format.
+// Secret attributes are redacted, see IsSecret.
+//
+// Parameters:
+//   - w: Where records are written, usually os.Stderr
+//   - level: The lowest level that is written
+//   - format: FormatText or FormatJSON
+//
+// Returns:
+//   - *slog.Logger: The logger
+//   - error: An error if the format is unknown
+func New(w io.Writer, level slog.Level, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected %s or %s)", format, FormatText, FormatJSON)
+	}
+}
+
+// ParseLevel parses a level name: debug, info, warn (or warning) or error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", name)
+	}
+}
+
+// IsSecret reports whether an attribute key names a secret: a token, password,
+// passphrase, secret or authorization header.
+func IsSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"token", "password", "passphrase", "secret", "authorization"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr replaces the values of secret attributes, see IsSecret.
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && IsSecret(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+// LogRequest logs a completed HTTP call: its method, host and path, the HTTP status,
+// the API's result code if the body is an API response, the number of attempts and
+// the latency. The query string is left out, since signed media URLs carry
+// credentials in it. Calls are logged at info level, and at warn level if they
+// failed or returned an error status.
+//
+// Parameters:
+//   - ctx: The context of the call
+//   - method: The HTTP method
+//   - rawURL: The request URL
+//   - status: The HTTP status code, or 0 if no response was received
+//   - body: The response body, or nil if it is not an API response
+//   - attempts: How often the request was sent
+//   - latency: The time taken by all attempts
+//   - err: The error that ended the call, or nil
+func LogRequest(ctx context.Context, method, rawURL string, status int, body []byte, attempts int, latency time.Duration, err error) {
+	attrs := []slog.Attr{slog.String("method", method)}
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		attrs = append(attrs, slog.String("host", u.Host), slog.String("endpoint", u.Path))
+	}
+	if status != 0 {
+		attrs = append(attrs, slog.Int("status", status))
+	}
+	if result, ok := resultCode(body); ok {
+		attrs = append(attrs, slog.Int("result", result))
+	}
+	attrs = append(attrs,
+		slog.Int("attempts", attempts),
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+	)
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, Error(err))
+	} else if status >= 400 {
+		level = slog.LevelWarn
+	}
+	Logger().LogAttrs(ctx, level, "HTTP request", attrs...)
+}
+
+// Error returns an "error" attribute for err. The query string is removed from the
+// URL of a *url.Error, as LogRequest does for request URLs.
+func Error(err error) slog.Attr {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil && u.RawQuery != "" {
+			u.RawQuery = ""
+			stripped := &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+			return slog.String("error", strings.Replace(err.Error(), urlErr.Error(), stripped.Error(), 1))
+		}
+	}
+	return slog.String("error", err.Error())
+}
+
+// resultCode returns the result or code field of an API response body.
+func resultCode(body []byte) (int, bool) {
+	if len(body) == 0 || body[0] != '{' {
+		return 0, false
+	}
+	var envelope struct {
+		Result *int `json:"result"`
+		Code   *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return 0, false
+	}
+	switch {
+	case envelope.Code != nil:
+		return *envelope.Code, true
+	case envelope.Result != nil:
+		return *envelope.Result, true
+	default:
+		return 0, false
+	}
+}
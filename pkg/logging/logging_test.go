@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture installs a JSON logger writing to the returned buffer for the duration of the test.
+func capture(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := New(&buf, level, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLogger(logger)
+	t.Cleanup(func() { SetLogger(nil) })
+	return &buf
+}
+
+// lastRecord decodes the last record written to buf.
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatalf("log output is not JSON: %v\n%s", err, buf)
+	}
+	return record
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+	for name, want := range tests {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel accepted an unknown level")
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	for _, format := range []string{"", FormatText, "JSON"} {
+		if _, err := New(&buf, slog.LevelInfo, format); err != nil {
+			t.Errorf("New with format %q failed: %v", format, err)
+		}
+	}
+	if _, err := New(&buf, slog.LevelInfo, "xml"); err == nil {
+		t.Error("New accepted an unknown format")
+	}
+}
+
+func TestLoggerDiscardsByDefault(t *testing.T) {
+	SetLogger(nil)
+	if Logger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("the default logger is enabled, want it to discard everything")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	buf := capture(t, slog.LevelDebug)
+	Logger().Info("login",
+		"email", "me@example.com",
+		"password", "hunter2",
+		"authToken", "abc",
+		"Authorization", "Bearer abc",
+		slog.Group("request", "token", "def", "path", "/login"),
+	)
+
+	record := lastRecord(t, buf)
+	for _, key := range []string{"password", "authToken", "Authorization"} {
+		if record[key] != Redacted {
+			t.Errorf("%s = %v, want %s", key, record[key], Redacted)
+		}
+	}
+	if record["email"] != "me@example.com" {
+		t.Errorf("email = %v, want it unchanged", record["email"])
+	}
+	group, _ := record["request"].(map[string]interface{})
+	if group["token"] != Redacted || group["path"] != "/login" {
+		t.Errorf("request group = %v, want only the token redacted", group)
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	LogRequest(context.Background(), "POST", "https://api.example.com/device/list?sign=secret", 200,
+		[]byte(`{"result":-1001,"msg":"bad"}`), 2, 1500*time.Microsecond, nil)
+	record := lastRecord(t, buf)
+	want := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "HTTP request",
+		"method":     "POST",
+		"host":       "api.example.com",
+		"endpoint":   "/device/list",
+		"status":     float64(200),
+		"result":     float64(-1001),
+		"attempts":   float64(2),
+		"latency_ms": 1.5,
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("log contains the query string:\n%s", buf)
+	}
+
+	LogRequest(context.Background(), "GET", "https://cdn.example.com/a.jpg", 404, nil, 1, time.Millisecond, nil)
+	if record := lastRecord(t, buf); record["level"] != "WARN" || record["result"] != nil {
+		t.Errorf("record of a failed download = %v, want a warning without a result", record)
+	}
+}
+
+func TestError(t *testing.T) {
+	err := &url.Error{Op: "Get", URL: "https://cdn.example.com/a.jpg?signature=secret", Err: errors.New("connection reset")}
+	attr := Error(fmt.Errorf("downloading: %w", err))
+	if got, want := attr.Value.String(), `downloading: Get "https://cdn.example.com/a.jpg": connection reset`; got != want {
+		t.Errorf("Error = %q, want %q", got, want)
+	}
+
+	if got := Error(errors.New("plain")).Value.String(); got != "plain" {
+		t.Errorf("Error of a plain error = %q", got)
+	}
+}
@@ -0,0 +1,181 @@
+package mockserver
+
+import (
+	"fmt"
+	"time"
+)
+
+// species is a bird identification used when seeding fixture events.
+type species struct {
+	name       string
+	latin      string
+	confidence float64
+}
+
+// fixtureSpecies lists the birds that seeded events rotate through.
+// An empty name produces an event without any identification.
+var fixtureSpecies = []species{
+	{"Northern Cardinal", "Cardinalis cardinalis", 0.981},
+	{"Eastern Bluebird", "Sialia sialis", 0.962},
+	{"House Finch", "Haemorhous mexicanus", 0.917},
+	{"", "", 0},
+	{"Eastern Phoebe", "Sayornis phoebe", 0.996},
+	{"Carolina Chickadee", "Poecile carolinensis", 0.874},
+}
+
+// doorbellSubject is a non-bird detection used when seeding doorbell events.
+type doorbellSubject struct {
+	objectType string
+	name       string
+	confidence float64
+}
+
+// fixtureDoorbellSubjects lists the subjects that seeded doorbell events rotate through.
+var fixtureDoorbellSubjects = []doorbellSubject{
+	{"person", "Person", 0.97},
+	{"vehicle", "Car", 0.93},
+	{"package", "Package", 0.88},
+	{"pet", "Dog", 0.91},
+}
+
+// DefaultDevices returns the devices served by a new Server, in API response format.
+func DefaultDevices() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"serialNumber":   "854396ddc826ed6e3e4263fa067ee288",
+			"modelNo":        "CG625-BD-TNBD-SS2",
+			"deviceName":     "Birdy House",
+			"networkName":    "Rocinante",
+			"ip":             "192.168.10.223",
+			"batteryLevel":   100,
+			"locationName":   "Garden",
+			"signalStrength": -54,
+			"wifiChannel":    6,
+			"isCharging":     0,
+			"chargingMode":   0,
+			"macAddress":     "b4:61:e9:72:a0:15",
+		},
+		{
+			"serialNumber":   "378b660598295ceca8b20871991a0409",
+			"modelNo":        "CG623G-ST1BQJ",
+			"deviceName":     "Birdies",
+			"networkName":    "Rocinante",
+			"ip":             "192.168.10.107",
+			"batteryLevel":   87,
+			"locationName":   "Garden",
+			"signalStrength": -51,
+			"wifiChannel":    6,
+			"isCharging":     1,
+			"chargingMode":   2,
+			"macAddress":     "b4:61:e9:35:7d:af",
+		},
+		{
+			"serialNumber":   "c1d3e8a0f44b4f0c9b2e7a5d6f801b22",
+			"modelNo":        "DB310-WF",
+			"deviceName":     "Front Door",
+			"networkName":    "Rocinante",
+			"ip":             "192.168.10.54",
+			"batteryLevel":   64,
+			"locationName":   "Porch",
+			"signalStrength": -62,
+			"wifiChannel":    11,
+			"isCharging":     0,
+			"chargingMode":   0,
+			"macAddress":     "b4:61:e9:0c:41:9e",
+		},
+	}
+}
+
+// DefaultEvents returns the events served by a new Server, in API response format.
+// A bird feeder visit is generated every three hours for the seven days before now,
+// alternating between the two feeder devices and rotating through a fixed list of
+// species, with every fifth visit shared by two species. The doorbell device records
+// a person, vehicle, package or pet every six hours. The CLI's default 24 hour window
+// therefore always contains data. Media URLs are paths under /media/ and are made
+// absolute when served.
+func DefaultEvents(now time.Time) []map[string]interface{} {
+	devices := DefaultDevices()
+	feeders, doorbell := devices[:2], devices[2]
+	now = now.Truncate(time.Hour)
+
+	var events []map[string]interface{}
+	for i := 0; i < 7*8; i++ {
+		ts := now.Add(-time.Duration(i*3)*time.Hour - 17*time.Minute)
+		device := feeders[i%len(feeders)]
+		bird := fixtureSpecies[i%len(fixtureSpecies)]
+		traceID := fmt.Sprintf("mock%010d%05d", ts.Unix(), i)
+
+		event := map[string]interface{}{
+			"traceId":      traceID,
+			"timestamp":    ts.Unix(),
+			"deviceName":   device["deviceName"],
+			"serialNumber": device["serialNumber"],
+			"adminName":    "demo",
+			"period":       12.5 + float64(i%7),
+			"imageUrl":     mediaPath + traceID + "_gallery.jpg",
+			"videoUrl":     mediaPath + traceID + ".m3u8",
+		}
+
+		if bird.name != "" {
+			subjects := []species{bird}
+			// Every fifth visit is shared with a second species
+			if extra := fixtureSpecies[(i+2)%len(fixtureSpecies)]; i%5 == 0 && extra.name != "" {
+				subjects = append(subjects, extra)
+			}
+
+			var detections, keyshots []interface{}
+			for n, subject := range subjects {
+				detections = append(detections, map[string]interface{}{
+					"objectType":  "bird",
+					"objectName":  subject.name,
+					"birdStdName": subject.latin,
+					"confidence":  subject.confidence,
+				})
+				keyshots = append(keyshots, map[string]interface{}{
+					"imageUrl":        fmt.Sprintf("%skeyshot_front_bird_%s_%d.jpg", mediaPath, traceID, n),
+					"message":         subject.name,
+					"objectCategory":  "bird",
+					"subCategoryName": subject.name,
+				})
+			}
+			event["subcategoryInfoList"] = detections
+			event["keyshots"] = keyshots
+		}
+
+		events = append(events, event)
+	}
+
+	for i := 0; i < 7*4; i++ {
+		ts := now.Add(-time.Duration(i*6)*time.Hour - 47*time.Minute)
+		subject := fixtureDoorbellSubjects[i%len(fixtureDoorbellSubjects)]
+		traceID := fmt.Sprintf("door%010d%05d", ts.Unix(), i)
+
+		events = append(events, map[string]interface{}{
+			"traceId":      traceID,
+			"timestamp":    ts.Unix(),
+			"deviceName":   doorbell["deviceName"],
+			"serialNumber": doorbell["serialNumber"],
+			"adminName":    "demo",
+			"period":       8.0 + float64(i%5),
+			"imageUrl":     mediaPath + traceID + "_gallery.jpg",
+			"videoUrl":     mediaPath + traceID + ".m3u8",
+			"subcategoryInfoList": []interface{}{
+				map[string]interface{}{
+					"objectType": subject.objectType,
+					"objectName": subject.name,
+					"confidence": subject.confidence,
+				},
+			},
+			"keyshots": []interface{}{
+				map[string]interface{}{
+					"imageUrl":        fmt.Sprintf("%skeyshot_front_%s_%s_0.jpg", mediaPath, subject.objectType, traceID),
+					"message":         subject.name,
+					"objectCategory":  subject.objectType,
+					"subCategoryName": subject.name,
+				},
+			},
+		})
+	}
+
+	return events
+}
@@ -0,0 +1,73 @@
+package mockserver
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// mediaPath is the path prefix under which the server serves event media.
+const mediaPath = "/media/"
+
+// handleMedia serves placeholder media for the fixture events. Images are served
+// as small binary blobs and videos as a two-segment HLS playlist. Range requests
+// are supported so that resumable downloads can be exercised.
+func handleMedia(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, mediaPath)
+
+	var content []byte
+	switch {
+	case strings.HasSuffix(name, ".m3u8"):
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		base := strings.TrimSuffix(name, ".m3u8")
+		content = []byte(fmt.Sprintf("#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\n%s_0.ts\n#EXTINF:6.0,\n%s_1.ts\n#EXT-X-ENDLIST\n", base, base))
+	case strings.HasSuffix(name, ".ts"):
+		w.Header().Set("Content-Type", "video/mp2t")
+		content = bytes.Repeat([]byte(name), 64)
+	case strings.HasSuffix(name, ".jpg"):
+		w.Header().Set("Content-Type", "image/jpeg")
+		content = append([]byte{0xFF, 0xD8, 0xFF, 0xE0}, bytes.Repeat([]byte(name), 32)...)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// withMediaHost returns a copy of an event whose media paths are absolute URLs on
+// the host that received the request.
+func withMediaHost(event map[string]interface{}, r *http.Request) map[string]interface{} {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return absoluteMedia(event, scheme+"://"+r.Host).(map[string]interface{})
+}
+
+// absoluteMedia recursively copies a decoded JSON value, prefixing media paths with base.
+func absoluteMedia(value interface{}, base string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = absoluteMedia(item, base)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = absoluteMedia(item, base)
+		}
+		return copied
+	case string:
+		if strings.HasPrefix(v, mediaPath) {
+			return base + v
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,353 @@
+// Package mockserver provides a fake Vicohome API for offline testing and demos.
+//
+// The Server type implements http.Handler and serves the login, event and device
+// endpoints used by the CLI from seeded fixture data. It can be mounted on an
+// httptest.Server in Go tests or run standalone through the mock-server command:
+//
+//	srv := httptest.NewServer(mockserver.New())
+//	defer srv.Close()
+//	c := client.New(auth.NewTokenSource(srv.URL))
+//	c.BaseURL = srv.URL
+//
+// The server can also simulate the API's authentication error codes so that
+// token refresh handling can be exercised without live credentials.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+)
+
+// Default credentials accepted by a new Server.
+const (
+	DefaultEmail    = "demo@example.com"
+	DefaultPassword = "demo"
+)
+
+// ResultLoginFailed is the result code returned for rejected credentials.
+const ResultLoginFailed = -1001
+
+// Server is a fake Vicohome API. All methods are safe for concurrent use.
+type Server struct {
+	Email    string        // Email accepted by /account/login
+	Password string        // Password accepted by /account/login
+	TokenTTL time.Duration // Lifetime of issued tokens; zero means tokens never expire
+
+	mu        sync.Mutex
+	mux       *http.ServeMux
+	devices   []map[string]interface{}
+	events    []map[string]interface{}
+	tokens    map[string]time.Time // issued token -> expiry (zero for no expiry)
+	revoked   map[string]bool
+	nextToken int
+	authFails []int // auth error codes to return for the next data requests
+	httpFails []int // HTTP status codes to return for the next requests
+}
+
+// New creates a Server seeded with DefaultDevices and DefaultEvents that accepts
+// DefaultEmail and DefaultPassword.
+func New() *Server {
+	s := &Server{
+		Email:    DefaultEmail,
+		Password: DefaultPassword,
+		devices:  DefaultDevices(),
+		events:   DefaultEvents(time.Now()),
+		tokens:   make(map[string]time.Time),
+		revoked:  make(map[string]bool),
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/account/login", s.handleLogin)
+	s.mux.HandleFunc("/library/newselectlibrary", s.authorized(s.handleListEvents))
+	s.mux.HandleFunc("/library/newselectsinglelibrary", s.authorized(s.handleGetEvent))
+	s.mux.HandleFunc("/device/listuserdevices", s.authorized(s.handleListDevices))
+	s.mux.HandleFunc("/device/selectsingledevice", s.authorized(s.handleGetDevice))
+	s.mux.HandleFunc(mediaPath, handleMedia)
+	return s
+}
+
+// ServeHTTP dispatches a request to the matching API endpoint, unless a simulated
+// HTTP failure is pending.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if status := s.nextHTTPFailure(); status != 0 {
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "1")
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// nextHTTPFailure returns the pending simulated HTTP failure, or 0 if there is none.
+func (s *Server) nextHTTPFailure() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.httpFails) == 0 {
+		return 0
+	}
+	status := s.httpFails[0]
+	s.httpFails = s.httpFails[1:]
+	return status
+}
+
+// SetDevices replaces the devices served by the server. Devices use the API's
+// response format, as returned by DefaultDevices.
+func (s *Server) SetDevices(devices []map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices = devices
+}
+
+// SetEvents replaces the events served by the server. Events use the API's
+// response format, as returned by DefaultEvents.
+func (s *Server) SetEvents(events []map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = events
+}
+
+// FailAuth makes the next n authenticated requests fail with the given result code,
+// regardless of the token they carry. Use the auth.Error* codes (-1024 to -1027)
+// to exercise token refresh handling.
+func (s *Server) FailAuth(code int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.authFails = append(s.authFails, code)
+	}
+}
+
+// FailHTTP makes the next n requests to any endpoint, including logins and media,
+// fail with the given HTTP status code. Responses with status 429 or 503 ask the
+// client to retry after one second. Use it to exercise retry handling.
+func (s *Server) FailHTTP(status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.httpFails = append(s.httpFails, status)
+	}
+}
+
+// RevokeTokens invalidates every token issued so far, as if the account had been
+// logged in elsewhere. Subsequent requests with those tokens fail with
+// auth.ErrorAccountKicked until the client logs in again.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token := range s.tokens {
+		s.revoked[token] = true
+	}
+}
+
+// handleLogin implements /account/login.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req auth.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResult(w, -1, "invalid request body", nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Email != s.Email || req.Password != s.Password {
+		writeResult(w, ResultLoginFailed, "account or password error", nil)
+		return
+	}
+
+	s.nextToken++
+	token := fmt.Sprintf("mock-token-%d", s.nextToken)
+	var expiresAt time.Time
+	if s.TokenTTL > 0 {
+		expiresAt = time.Now().Add(s.TokenTTL)
+	}
+	s.tokens[token] = expiresAt
+
+	// Report the expiry of tokens with a limited lifetime, as Unix seconds
+	tokenObj := map[string]interface{}{
+		"token": token,
+	}
+	if !expiresAt.IsZero() {
+		tokenObj["expireTime"] = expiresAt.Unix()
+	}
+
+	writeResult(w, 0, "Success", map[string]interface{}{
+		"token": tokenObj,
+	})
+}
+
+// authorized wraps a handler with token validation and simulated auth failures.
+// The request body is decoded into a generic map and passed to the handler.
+func (s *Server) authorized(next func(http.ResponseWriter, *http.Request, map[string]interface{})) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if code, msg := s.checkToken(r.Header.Get("Authorization")); code != 0 {
+			writeResult(w, code, msg, nil)
+			return
+		}
+
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeResult(w, -1, "invalid request body", nil)
+			return
+		}
+		next(w, r, body)
+	}
+}
+
+// checkToken returns the auth error code and message for a token, or 0 if it is accepted.
+func (s *Server) checkToken(token string) (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.authFails) > 0 {
+		code := s.authFails[0]
+		s.authFails = s.authFails[1:]
+		return code, authErrorMessage(code)
+	}
+
+	if token == "" {
+		return auth.ErrorTokenMissing, authErrorMessage(auth.ErrorTokenMissing)
+	}
+	expiresAt, ok := s.tokens[token]
+	if !ok {
+		return auth.ErrorTokenInvalid, authErrorMessage(auth.ErrorTokenInvalid)
+	}
+	if s.revoked[token] {
+		return auth.ErrorAccountKicked, authErrorMessage(auth.ErrorAccountKicked)
+	}
+	if !expiresAt.IsZero() && time.Now().After(expiresAt) {
+		return auth.ErrorTokenExpired, authErrorMessage(auth.ErrorTokenExpired)
+	}
+	return 0, ""
+}
+
+// handleListEvents implements /library/newselectlibrary.
+// Events are filtered by the startTimestamp/endTimestamp window and returned newest first,
+// limited to the from/to offset range when the request specifies one.
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	start := int64Field(body, "startTimestamp")
+	end := int64Field(body, "endTimestamp")
+
+	s.mu.Lock()
+	var matched []map[string]interface{}
+	for _, event := range s.events {
+		ts := int64Field(event, "timestamp")
+		if ts >= start && ts <= end {
+			matched = append(matched, withMediaHost(event, r))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return int64Field(matched[i], "timestamp") > int64Field(matched[j], "timestamp")
+	})
+
+	total := len(matched)
+	if to := int(int64Field(body, "to")); to > 0 {
+		from := int(int64Field(body, "from"))
+		if from > total {
+			from = total
+		}
+		if to > total {
+			to = total
+		}
+		if from > to {
+			from = to
+		}
+		matched = matched[from:to]
+	}
+
+	writeResult(w, 0, "Success", map[string]interface{}{
+		"list":  matched,
+		"total": total,
+	})
+}
+
+// handleGetEvent implements /library/newselectsinglelibrary.
+func (s *Server) handleGetEvent(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	traceID, _ := body["traceId"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event["traceId"] == traceID {
+			writeResult(w, 0, "Success", withMediaHost(event, r))
+			return
+		}
+	}
+	writeResult(w, 0, "Success", map[string]interface{}{})
+}
+
+// handleListDevices implements /device/listuserdevices.
+func (s *Server) handleListDevices(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeResult(w, 0, "Success", map[string]interface{}{
+		"list": s.devices,
+	})
+}
+
+// handleGetDevice implements /device/selectsingledevice.
+func (s *Server) handleGetDevice(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	serialNumber, _ := body["serialNumber"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, device := range s.devices {
+		if device["serialNumber"] == serialNumber {
+			writeResult(w, 0, "Success", device)
+			return
+		}
+	}
+	writeResult(w, 0, "Success", nil)
+}
+
+// writeResult writes the API's standard response envelope.
+func writeResult(w http.ResponseWriter, result int, msg string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": result,
+		"msg":    msg,
+		"data":   data,
+	})
+}
+
+// authErrorMessage returns the message the API sends with an auth error code.
+func authErrorMessage(code int) string {
+	switch code {
+	case auth.ErrorAccountKicked:
+		return "account kicked offline"
+	case auth.ErrorTokenMissing:
+		return "token missing"
+	case auth.ErrorTokenInvalid:
+		return "token invalid"
+	case auth.ErrorTokenExpired:
+		return "token expired"
+	default:
+		return "auth error"
+	}
+}
+
+// int64Field reads an integer field that may be encoded as a JSON number or a numeric string.
+func int64Field(m map[string]interface{}, key string) int64 {
+	switch val := m[key].(type) {
+	case float64:
+		return int64(val)
+	case int64:
+		return val
+	case int:
+		return int64(val)
+	case string:
+		n, _ := strconv.ParseInt(val, 10, 64)
+		return n
+	}
+	return 0
+}
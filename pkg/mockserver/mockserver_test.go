@@ -0,0 +1,143 @@
+package mockserver_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/auth"
+	"github.com/dydx/vico-cli/pkg/client"
+	"github.com/dydx/vico-cli/pkg/mockserver"
+	"github.com/dydx/vico-cli/pkg/retry"
+)
+
+// newClient starts mock on an httptest server and returns a client logged in with
+// the given password. Tokens are cached in memory only.
+func newClient(t *testing.T, mock *mockserver.Server, password string) *client.Client {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VICOHOME_TOKEN_STORE", "memory")
+
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+
+	source := auth.NewTokenSource(srv.URL)
+	source.Email = mockserver.DefaultEmail
+	source.Password = password
+	source.Retry = retry.Policy{}
+
+	c := client.New(source)
+	c.BaseURL = srv.URL
+	c.Retry = retry.Policy{
+		MaxAttempts:     3,
+		InitialBackoff:  time.Millisecond,
+		RetryableStatus: retry.DefaultRetryableStatus,
+	}
+	return c
+}
+
+func TestClientAgainstMockServer(t *testing.T) {
+	c := newClient(t, mockserver.New(), mockserver.DefaultPassword)
+	ctx := context.Background()
+
+	devices, err := c.ListDevices(ctx)
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(devices) != len(mockserver.DefaultDevices()) {
+		t.Fatalf("ListDevices returned %d devices, want %d", len(devices), len(mockserver.DefaultDevices()))
+	}
+	device, err := c.GetDevice(ctx, devices[0].SerialNumber)
+	if err != nil || device.DeviceName != devices[0].DeviceName {
+		t.Errorf("GetDevice = %+v, %v, want %q", device, err, devices[0].DeviceName)
+	}
+
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+	events, err := c.ListEvents(ctx, start, end)
+	if err != nil {
+		t.Fatalf("ListEvents failed: %v", err)
+	}
+	if len(events) == 0 {
+		t.Fatal("ListEvents returned no events for the last day")
+	}
+	if len(events) >= len(mockserver.DefaultEvents(end)) {
+		t.Errorf("ListEvents returned all %d events for the last day, want only those of the day", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Timestamp > events[i-1].Timestamp {
+			t.Fatalf("events are not ordered newest first: %s after %s", events[i].Timestamp, events[i-1].Timestamp)
+		}
+	}
+
+	event, err := c.GetEvent(ctx, events[0].TraceID)
+	if err != nil || event.TraceID != events[0].TraceID {
+		t.Errorf("GetEvent = %+v, %v, want %q", event, err, events[0].TraceID)
+	}
+	if _, err := c.GetEvent(ctx, "no-such-event"); err == nil {
+		t.Error("GetEvent of an unknown trace ID succeeded")
+	}
+}
+
+func TestMockServerPaging(t *testing.T) {
+	mock := mockserver.New()
+	c := newClient(t, mock, mockserver.DefaultPassword)
+	c.PageSize = 7
+
+	end := time.Now()
+	events, err := c.ListEvents(context.Background(), end.Add(-7*24*time.Hour), end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(mockserver.DefaultEvents(end)); len(events) != want {
+		t.Errorf("ListEvents returned %d events in pages of 7, want all %d", len(events), want)
+	}
+}
+
+func TestMockServerRejectsCredentials(t *testing.T) {
+	c := newClient(t, mockserver.New(), "wrong")
+
+	_, err := c.ListDevices(context.Background())
+	var authErr *auth.AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("ListDevices with a wrong password = %v, want an AuthError", err)
+	}
+}
+
+func TestMockServerSimulatedFailures(t *testing.T) {
+	mock := mockserver.New()
+	c := newClient(t, mock, mockserver.DefaultPassword)
+	ctx := context.Background()
+
+	if _, err := c.ListDevices(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A revoked token is replaced by logging in again
+	mock.RevokeTokens()
+	if _, err := c.ListDevices(ctx); err != nil {
+		t.Errorf("ListDevices after the tokens were revoked failed: %v", err)
+	}
+
+	// A simulated auth error is retried with a fresh token
+	mock.FailAuth(auth.ErrorTokenInvalid, 1)
+	if _, err := c.ListDevices(ctx); err != nil {
+		t.Errorf("ListDevices after a simulated auth error failed: %v", err)
+	}
+
+	// Transient HTTP failures are retried
+	mock.FailHTTP(http.StatusBadGateway, 2)
+	if _, err := c.ListDevices(ctx); err != nil {
+		t.Errorf("ListDevices after two bad gateway responses failed: %v", err)
+	}
+
+	mock.FailHTTP(http.StatusInternalServerError, 3)
+	_, err := c.ListDevices(ctx)
+	var apiErr *auth.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusInternalServerError {
+		t.Errorf("ListDevices after the retries were used up = %v, want an APIError with status 500", err)
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+// Device represents a Vicohome device with its properties as returned by the API.
+// This structure contains essential information about a device that can be displayed
+// to the user or used for further API calls.
+type Device struct {
+	SerialNumber   string `json:"serialNumber"`
+	ModelNo        string `json:"modelNo"`
+	DeviceName     string `json:"deviceName"`
+	NetworkName    string `json:"networkName"`
+	IP             string `json:"ip"`
+	BatteryLevel   int    `json:"batteryLevel"`
+	LocationName   string `json:"locationName"`
+	SignalStrength int    `json:"signalStrength"`
+	WifiChannel    int    `json:"wifiChannel"`
+	IsCharging     int    `json:"isCharging"`
+	ChargingMode   int    `json:"chargingMode"`
+	MacAddress     string `json:"macAddress"`
+}
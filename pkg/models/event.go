@@ -1,9 +1,59 @@
 // Package models provides data models for the Vicohome CLI application.
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Detection categories. Categories reported by the API are normalized to one of
+// these by NormalizeCategory; unknown categories are kept as reported.
+const (
+	CategoryBird    = "bird"
+	CategoryPerson  = "person"
+	CategoryVehicle = "vehicle"
+	CategoryAnimal  = "animal"
+	CategoryPackage = "package"
+)
+
+// Categories lists the detection categories in the order they are documented.
+var Categories = []string{CategoryBird, CategoryPerson, CategoryVehicle, CategoryAnimal, CategoryPackage}
+
+// categoryAliases maps object types reported by the API to their normalized category.
+var categoryAliases = map[string]string{
+	"human": CategoryPerson,
+	"car":   CategoryVehicle,
+	"pet":   CategoryAnimal,
+	"dog":   CategoryAnimal,
+	"cat":   CategoryAnimal,
+}
+
+// NormalizeCategory converts an object type or category reported by the API into
+// one of the Category constants. Unknown values are returned lower-cased.
+func NormalizeCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if normalized, ok := categoryAliases[category]; ok {
+		return normalized
+	}
+	return category
+}
+
+// CheckCategory returns an error if category does not normalize to one of Categories,
+// so that a mistyped filter is reported instead of matching no events.
+func CheckCategory(category string) error {
+	normalized := NormalizeCategory(category)
+	for _, known := range Categories {
+		if normalized == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown category %q (available: %s)", category, strings.Join(Categories, ", "))
+}
+
 // Event represents a Vicohome event with its properties as returned by the API.
-// This structure contains information about bird sightings, including metadata
-// about the device that captured the event, the bird identified, and media URLs.
+// This structure contains information about bird sightings and other detections,
+// including metadata about the device that captured the event, the subjects
+// identified, and media URLs.
 type Event struct {
 	TraceID        string  `json:"traceId"`
 	Timestamp      string  `json:"timestamp"`
@@ -11,6 +61,7 @@ type Event struct {
 	SerialNumber   string  `json:"serialNumber"`
 	AdminName      string  `json:"adminName"`
 	Period         string  `json:"period"`
+	Category       string  `json:"category"` // Category of the top detection, e.g. "bird" or "person"
 	BirdName       string  `json:"birdName"`
 	BirdLatin      string  `json:"birdLatin"`
 	BirdConfidence float64 `json:"birdConfidence"`
@@ -18,6 +69,48 @@ type Event struct {
 	ImageURL       string  `json:"imageUrl"`
 	VideoURL       string  `json:"videoUrl"`
 
-	// Internal field - not exported to JSON
-	keyshots []map[string]interface{} `json:"-"`
+	// Detections holds every subject identified in the event, highest confidence
+	// first. BirdName, BirdLatin and BirdConfidence describe the top bird among them.
+	Detections []Detection `json:"detections"`
+
+	// Keyshots holds every detection frame captured for the event.
+	// KeyShotURL is the image URL of the first of them.
+	Keyshots []Keyshot `json:"keyshots"`
+}
+
+// Detection is a single subject identified in an event.
+type Detection struct {
+	ObjectType string  `json:"objectType"` // Normalized category of the subject, e.g. "bird"
+	Name       string  `json:"name"`       // Common name of the subject
+	LatinName  string  `json:"latinName"`  // Scientific name, for birds
+	Confidence float64 `json:"confidence"` // Identification confidence between 0 and 1
+}
+
+// HasCategory reports whether any detection or keyshot of the event belongs to the
+// given category. The category is normalized before comparison.
+func (e Event) HasCategory(category string) bool {
+	category = NormalizeCategory(category)
+	if e.Category == category {
+		return true
+	}
+	for _, detection := range e.Detections {
+		if detection.ObjectType == category {
+			return true
+		}
+	}
+	for _, keyshot := range e.Keyshots {
+		if NormalizeCategory(keyshot.ObjectCategory) == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Keyshot is a single detection frame captured during an event, along with
+// what was detected in it.
+type Keyshot struct {
+	ImageURL        string `json:"imageUrl"`
+	Message         string `json:"message"`
+	ObjectCategory  string `json:"objectCategory"`
+	SubCategoryName string `json:"subCategoryName"`
 }
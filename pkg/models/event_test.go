@@ -0,0 +1,16 @@
+package models
+
+import "testing"
+
+func TestCheckCategory(t *testing.T) {
+	for _, category := range []string{"bird", "Person", " vehicle ", "dog", "car", "human", "package"} {
+		if err := CheckCategory(category); err != nil {
+			t.Errorf("CheckCategory(%q) = %v", category, err)
+		}
+	}
+	for _, category := range []string{"brid", "", "birds", "unknown"} {
+		if err := CheckCategory(category); err == nil {
+			t.Errorf("CheckCategory(%q) accepted an unknown category", category)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package jsonpath implements the JSONPath template syntax used by kubectl's
+// -o jsonpath output, for selecting fields from decoded JSON values.
+//
+// A template is plain text with expressions in braces, for example
+// "{.deviceName}: {.batteryLevel}%". Inside braces the following are supported:
+//
+//   - .name or ['name']  a field of an object
+//   - [n]                an element of an array; negative indexes count from the end
+//   - [*] or .*          every element of an array or value of an object
+//   - ..name             the named field at any depth
+//   - "text"             a quoted string literal, such as "\n" or "\t"
+//
+// Expressions that select several values print them separated by spaces. Fields
+// that do not exist select nothing and print nothing.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stepKind identifies the operation performed by a step of a path.
+type stepKind int
+
+const (
+	fieldStep     stepKind = iota // Select a named field of an object
+	indexStep                     // Select an element of an array
+	wildcardStep                  // Select every element or value
+	recursiveStep                 // Select a named field at any depth
+)
+
+// step is a single operation of a path.
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+}
+
+// segment is a part of a template: literal text, or a path to evaluate.
+type segment struct {
+	text   string
+	path   []step
+	isPath bool
+}
+
+// Template is a parsed JSONPath template.
+type Template struct {
+	segments []segment
+}
+
+// Parse parses a JSONPath template.
+//
+// Parameters:
+//   - text: The template, such as "{.serialNumber}"
+//
+// Returns:
+//   - *Template: The parsed template
+//   - error: An error if a brace is unbalanced or an expression is invalid
+func Parse(text string) (*Template, error) {
+	t := &Template{}
+	for len(text) > 0 {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			t.segments = append(t.segments, segment{text: text})
+			break
+		}
+		if open > 0 {
+			t.segments = append(t.segments, segment{text: text[:open]})
+		}
+
+		end := closingBrace(text, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed expression in JSONPath template %q", text)
+		}
+
+		expr := strings.TrimSpace(text[open+1 : end])
+		if strings.HasPrefix(expr, `"`) {
+			literal, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string literal %s in JSONPath template: %w", expr, err)
+			}
+			t.segments = append(t.segments, segment{text: literal})
+		} else {
+			path, err := parsePath(expr)
+			if err != nil {
+				return nil, err
+			}
+			t.segments = append(t.segments, segment{path: path, isPath: true})
+		}
+		text = text[end+1:]
+	}
+	return t, nil
+}
+
+// closingBrace returns the index of the brace closing the one at open, skipping
+// braces inside quoted strings, or -1 if there is none.
+func closingBrace(text string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// parsePath parses the expression inside braces into steps.
+func parsePath(expr string) ([]step, error) {
+	original := expr
+	expr = strings.TrimPrefix(expr, "$")
+
+	var path []step
+	for len(expr) > 0 {
+		switch {
+		case strings.HasPrefix(expr, ".."):
+			name, rest := splitName(expr[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after .. in JSONPath expression %q", original)
+			}
+			path = append(path, step{kind: recursiveStep, name: name})
+			expr = rest
+		case strings.HasPrefix(expr, ".*"):
+			path = append(path, step{kind: wildcardStep})
+			expr = expr[2:]
+		case expr[0] == '.':
+			name, rest := splitName(expr[1:])
+			// A lone "." selects the current value
+			if name != "" {
+				path = append(path, step{kind: fieldStep, name: name})
+			}
+			expr = rest
+		case expr[0] == '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in JSONPath expression %q", original)
+			}
+			s, err := parseBracket(strings.TrimSpace(expr[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("%w in JSONPath expression %q", err, original)
+			}
+			path = append(path, s)
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in JSONPath expression %q", expr, original)
+		}
+	}
+	return path, nil
+}
+
+// splitName splits a field name from the start of expr, returning the name and the rest.
+func splitName(expr string) (string, string) {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		return expr, ""
+	}
+	return expr[:end], expr[end:]
+}
+
+// parseBracket parses the contents of a [...] step.
+func parseBracket(inner string) (step, error) {
+	if inner == "*" {
+		return step{kind: wildcardStep}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return step{kind: fieldStep, name: inner[1 : len(inner)-1]}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return step{}, fmt.Errorf("unsupported subscript [%s]", inner)
+	}
+	return step{kind: indexStep, index: index}, nil
+}
+
+// Execute evaluates the template against data and writes the result to w.
+// data is a decoded JSON value, such as the result of json.Unmarshal into an interface{}.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	var b strings.Builder
+	for _, seg := range t.segments {
+		if !seg.isPath {
+			b.WriteString(seg.text)
+			continue
+		}
+
+		values := evaluate(seg.path, data)
+		for i, value := range values {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			text, err := format(value)
+			if err != nil {
+				return err
+			}
+			b.WriteString(text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// evaluate applies the steps of a path to data and returns the selected values.
+func evaluate(path []step, data interface{}) []interface{} {
+	values := []interface{}{data}
+	for _, s := range path {
+		var next []interface{}
+		for _, value := range values {
+			next = append(next, apply(s, value)...)
+		}
+		values = next
+	}
+	return values
+}
+
+// apply applies a single step to a value.
+func apply(s step, value interface{}) []interface{} {
+	switch s.kind {
+	case fieldStep:
+		if object, ok := value.(map[string]interface{}); ok {
+			if field, ok := object[s.name]; ok {
+				return []interface{}{field}
+			}
+		}
+	case indexStep:
+		if array, ok := value.([]interface{}); ok {
+			index := s.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []interface{}{array[index]}
+			}
+		}
+	case wildcardStep:
+		return children(value)
+	case recursiveStep:
+		var found []interface{}
+		if object, ok := value.(map[string]interface{}); ok {
+			if field, ok := object[s.name]; ok {
+				found = append(found, field)
+			}
+		}
+		for _, child := range children(value) {
+			found = append(found, apply(s, child)...)
+		}
+		return found
+	}
+	return nil
+}
+
+// children returns the elements of an array or the values of an object, in key order.
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	}
+	return nil
+}
+
+// format converts a selected value to text. Strings and numbers are printed as-is,
+// and objects and arrays as compact JSON.
+func format(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("error formatting JSONPath result: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
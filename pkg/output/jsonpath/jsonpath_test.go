@@ -0,0 +1,108 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testData = `{
+	"serialNumber": "SN1",
+	"batteryLevel": 85,
+	"online": true,
+	"location": null,
+	"bird": {"name": "Robin", "confidence": 0.9},
+	"tags": ["garden", "front"],
+	"devices": [
+		{"name": "Feeder", "battery": 85, "wifi": {"ssid": "home"}},
+		{"name": "Porch", "battery": 40.5, "wifi": {"ssid": "guest"}}
+	],
+	"odd key": "spaced"
+}`
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.serialNumber}", "SN1"},
+		{"{$.serialNumber}", "SN1"},
+		{"{.serialNumber}: {.batteryLevel}%", "SN1: 85%"},
+		{"{.online}", "true"},
+		{"{.location}", ""},
+		{"{.missing}", ""},
+		{"{.bird.name}", "Robin"},
+		{"{.bird}", `{"confidence":0.9,"name":"Robin"}`},
+		{"{.tags}", `["garden","front"]`},
+		{"{.tags[0]}", "garden"},
+		{"{.tags[-1]}", "front"},
+		{"{.tags[5]}", ""},
+		{"{.tags[*]}", "garden front"},
+		{"{.devices[*].name}", "Feeder Porch"},
+		{"{.devices[*].battery}", "85 40.5"},
+		{"{.devices[1]['name']}", "Porch"},
+		{`{["odd key"]}`, "spaced"},
+		{"{.bird.*}", "0.9 Robin"},
+		{"{..ssid}", "home guest"},
+		{`{.devices[0].name}{"\t"}{.devices[1].name}{"\n"}`, "Feeder\tPorch\n"},
+		{`{"}"}`, "}"},
+		{"{ .serialNumber }", "SN1"},
+		{"plain text", "plain text"},
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(testData), &data); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := Parse(tt.template)
+			if err != nil {
+				t.Fatalf("Parse error = %v", err)
+			}
+			var b strings.Builder
+			if err := tmpl.Execute(&b, data); err != nil {
+				t.Fatalf("Execute error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Execute = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteCurrentValue(t *testing.T) {
+	tmpl, err := Parse("{.}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, "just a string"); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "just a string" {
+		t.Errorf("Execute = %q, want the value itself", b.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  string
+	}{
+		{"{.name", "unclosed expression"},
+		{"{.tags[0}", "unclosed ["},
+		{"{.tags[first]}", "unsupported subscript [first]"},
+		{"{..}", "missing field name after .."},
+		{"{name}", `unexpected "name"`},
+		{`{"\q"}`, "invalid string literal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := Parse(tt.template)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
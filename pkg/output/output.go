@@ -1,32 +1,241 @@
 // Package output provides interfaces and implementations for different output formats and destinations.
+//
+// Formats are kept in a registry keyed by name. Commands obtain a Handler for the
+// format selected by the user through Factory, so a format registered once is
+// available to every command and behaves the same everywhere.
 package output
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/dydx/vico-cli/pkg/models"
 	"github.com/dydx/vico-cli/pkg/output/stdout"
 )
 
-// Handler defines the interface for handling event output.
-// Implementations of this interface can output events in different formats (e.g., table, JSON).
+// Handler defines the interface for writing command results.
+// Implementations of this interface can output results in different formats (e.g., table, JSON).
 type Handler interface {
-	// Write outputs the events using the configured format and destination.
-	Write(events []models.Event) error
+	// WriteEvents outputs a list of events.
+	WriteEvents(events []models.Event) error
+
+	// WriteEvent outputs the details of a single event.
+	WriteEvent(event models.Event) error
+
+	// WriteDevices outputs a list of devices.
+	WriteDevices(devices []models.Device) error
+
+	// WriteDevice outputs the details of a single device.
+	WriteDevice(device models.Device) error
 
 	// Close releases any resources used by the handler.
 	Close()
 }
 
-// Factory creates a Handler based on the specified format.
-func Factory(format string) (Handler, error) {
-	return NewStdoutHandler(format), nil
+// EventStreamer is implemented by handlers that can write the events of a list one
+// at a time, as they are fetched, instead of waiting for the complete list.
+type EventStreamer interface {
+	// StreamEvent outputs the next event of a list.
+	StreamEvent(event models.Event) error
+}
+
+// Record kinds a Handler can be created for, see Options.
+const (
+	KindEvents  = "events"  // Events, listed or one at a time
+	KindDevices = "devices" // Devices, listed or one at a time
+)
+
+// Options configures a Handler created by Factory.
+type Options struct {
+	// Kind is the kind of records the handler writes, KindEvents or KindDevices.
+	// Columns and SortBy must name columns of this kind; if Kind is empty, columns
+	// of either kind are accepted.
+	Kind string
+
+	Writer       io.Writer // Destination for the output; defaults to os.Stdout
+	EmptyMessage string    // Message shown by human-readable formats for empty lists
+	Columns      []string  // Columns to write, for formats that support selecting them
+	Template     string    // Template for formats that take one, such as template and jsonpath
+	NoHeaders    bool      // Omit header rows from tables and delimited output
+	Width        int       // Maximum table width in cells, or 0 for no limit
+	SortBy       string    // Column to sort lists by, or "" to keep the fetched order
+	Reverse      bool      // Sort lists in descending order, or reverse the fetched order
+}
+
+// Constructor creates a Handler for a registered format.
+// It returns an error if the options are not valid for the format.
+type Constructor func(opts Options) (Handler, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+func init() {
+	Register("table", func(opts Options) (Handler, error) {
+		handler, err := stdout.NewTableHandler(opts.Writer, opts.EmptyMessage, opts.Columns)
+		if err != nil {
+			return nil, err
+		}
+		handler.NoHeaders = opts.NoHeaders
+		handler.Width = opts.Width
+		return handler, nil
+	})
+	Register("json", func(opts Options) (Handler, error) {
+		return stdout.NewJSONHandler(opts.Writer), nil
+	})
+	Register("ndjson", func(opts Options) (Handler, error) {
+		return stdout.NewNDJSONHandler(opts.Writer), nil
+	})
+	Register("template", func(opts Options) (Handler, error) {
+		handler, err := stdout.NewTemplateHandler(opts.Writer, opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		return handler, nil
+	})
+	Register("jsonpath", func(opts Options) (Handler, error) {
+		handler, err := stdout.NewJSONPathHandler(opts.Writer, opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		return handler, nil
+	})
+	Register("csv", func(opts Options) (Handler, error) {
+		handler, err := stdout.NewCSVHandler(opts.Writer, opts.Columns)
+		if err != nil {
+			return nil, err
+		}
+		handler.NoHeaders = opts.NoHeaders
+		return handler, nil
+	})
+	Register("tsv", func(opts Options) (Handler, error) {
+		handler, err := stdout.NewTSVHandler(opts.Writer, opts.Columns)
+		if err != nil {
+			return nil, err
+		}
+		handler.NoHeaders = opts.NoHeaders
+		return handler, nil
+	})
+}
+
+// Register makes an output format available under the given name.
+// Registering a name twice replaces the earlier constructor.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(name)] = constructor
+}
+
+// Formats returns the names of all registered formats in sorted order.
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Factory creates a Handler for the named format.
+// A template can be given with the format as "name=template", as in
+// "jsonpath={.serialNumber}", in which case it replaces opts.Template.
+//
+// Parameters:
+//   - format: The name of a registered format (e.g., "table" or "json")
+//   - opts: Options passed to the format's constructor
+//
+// Returns:
+//   - Handler: The handler for the format
+//   - error: An error if the format is not registered or the options are invalid,
+//     including columns or a sort column that do not belong to opts.Kind
+func Factory(format string, opts Options) (Handler, error) {
+	name, template, hasTemplate := strings.Cut(format, "=")
+	if hasTemplate {
+		opts.Template = template
+	}
+
+	registryMu.RLock()
+	constructor, ok := registry[strings.ToLower(name)]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format %q (supported: %s)", name, strings.Join(Formats(), ", "))
+	}
+
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	if err := checkColumns(opts.Kind, opts.Columns); err != nil {
+		return nil, err
+	}
+	if opts.SortBy != "" {
+		if err := checkColumns(opts.Kind, []string{opts.SortBy}); err != nil {
+			return nil, fmt.Errorf("cannot sort by %q: %w", opts.SortBy, err)
+		}
+	}
+
+	handler, err := constructor(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SortBy != "" || opts.Reverse {
+		handler = &sortedHandler{Handler: handler, sortBy: opts.SortBy, reverse: opts.Reverse}
+	}
+	return handler, nil
+}
+
+// checkColumns returns an error if one of the names is not a column of the given
+// record kind. For an empty kind, the names must all be event columns or all be
+// device columns.
+func checkColumns(kind string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	_, eventErr := stdout.SelectEventColumns(names)
+	_, deviceErr := stdout.SelectDeviceColumns(names)
+	switch kind {
+	case KindEvents:
+		return eventErr
+	case KindDevices:
+		return deviceErr
+	}
+	if eventErr != nil && deviceErr != nil {
+		return eventErr
+	}
+	return nil
+}
+
+// sortedHandler sorts lists before passing them to the handler it wraps. It does not
+// implement EventStreamer, since a list can only be sorted once it is complete.
+type sortedHandler struct {
+	Handler
+	sortBy  string
+	reverse bool
+}
+
+// WriteEvents sorts a copy of the events and writes it to the wrapped handler.
+func (h *sortedHandler) WriteEvents(events []models.Event) error {
+	sorted := append([]models.Event(nil), events...)
+	if err := stdout.SortEvents(sorted, h.sortBy, h.reverse); err != nil {
+		return err
+	}
+	return h.Handler.WriteEvents(sorted)
 }
 
-// NewStdoutHandler creates a new stdout output handler.
-func NewStdoutHandler(format string) Handler {
-	switch format {
-	case "json":
-		return stdout.NewJSONHandler()
-	default:
-		return stdout.NewTableHandler()
+// WriteDevices sorts a copy of the devices and writes it to the wrapped handler.
+func (h *sortedHandler) WriteDevices(devices []models.Device) error {
+	sorted := append([]models.Device(nil), devices...)
+	if err := stdout.SortDevices(sorted, h.sortBy, h.reverse); err != nil {
+		return err
 	}
+	return h.Handler.WriteDevices(sorted)
 }
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+func TestFactoryChecksColumnsOfKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		opts    Options
+		wantErr string
+	}{
+		{name: "event sort column", format: "table", opts: Options{Kind: KindEvents, SortBy: "timestamp"}},
+		{name: "device sort column", format: "table", opts: Options{Kind: KindDevices, SortBy: "battery"}},
+		{name: "event sort column for devices", format: "table", opts: Options{Kind: KindDevices, SortBy: "timestamp"}, wantErr: `cannot sort by "timestamp"`},
+		{name: "device sort column for events", format: "json", opts: Options{Kind: KindEvents, SortBy: "battery"}, wantErr: `cannot sort by "battery"`},
+		{name: "event columns", format: "csv", opts: Options{Kind: KindEvents, Columns: []string{"traceId", "bird"}}},
+		{name: "event columns for devices", format: "csv", opts: Options{Kind: KindDevices, Columns: []string{"bird"}}, wantErr: `unknown device column "bird"`},
+		{name: "device columns for events", format: "table", opts: Options{Kind: KindEvents, Columns: []string{"serial", "battery"}}, wantErr: `unknown event column "battery"`},
+		{name: "any kind", format: "tsv", opts: Options{Columns: []string{"battery"}, SortBy: "battery"}},
+		{name: "unknown column of any kind", format: "tsv", opts: Options{Columns: []string{"colour"}}, wantErr: `unknown event column "colour"`},
+		{name: "unknown format", format: "yaml", opts: Options{Kind: KindEvents}, wantErr: `unsupported output format "yaml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Writer = &bytes.Buffer{}
+			handler, err := Factory(tt.format, tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Factory error = %v", err)
+				}
+				handler.Close()
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Factory error = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFactoryTemplateFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		opts   Options
+		want   string
+	}{
+		{format: "template={{.SerialNumber}}: {{.BatteryLevel}}", want: "SN1: 85\n"},
+		{format: "jsonpath={.serialNumber}: {.batteryLevel}", want: "SN1: 85\n"},
+		{format: "JSONPath={.deviceName}", want: "Feeder\n"},
+		// A template given with the format replaces the one given separately
+		{format: "template={{.DeviceName}}", opts: Options{Template: "{{.SerialNumber}}"}, want: "Feeder\n"},
+		{format: "template", opts: Options{Template: "{{.SerialNumber}}"}, want: "SN1\n"},
+		{format: "jsonpath", opts: Options{Template: "{.deviceName}"}, want: "Feeder\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.opts.Writer = &buf
+			tt.opts.Kind = KindDevices
+			handler, err := Factory(tt.format, tt.opts)
+			if err != nil {
+				t.Fatalf("Factory error = %v", err)
+			}
+			defer handler.Close()
+			if err := handler.WriteDevices([]models.Device{{SerialNumber: "SN1", DeviceName: "Feeder", BatteryLevel: 85}}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	for _, format := range []string{"template", "jsonpath", "jsonpath={.name"} {
+		if _, err := Factory(format, Options{Writer: &bytes.Buffer{}}); err == nil {
+			t.Errorf("Factory(%q) accepted a missing or invalid template", format)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package stdout
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+// EventColumn is a named field of an event that can be selected for output.
+type EventColumn struct {
+	Name   string                    // Name used in --columns and delimited header rows
+	Header string                    // Header shown in tables
+	Value  func(models.Event) string // Returns the field's value for an event
+}
+
+// DeviceColumn is a named field of a device that can be selected for output.
+type DeviceColumn struct {
+	Name   string                     // Name used in --columns and delimited header rows
+	Header string                     // Header shown in tables
+	Value  func(models.Device) string // Returns the field's value for a device
+}
+
+// EventColumns lists every column available for events, in default output order.
+var EventColumns = []EventColumn{
+	{"traceId", "Trace ID", func(e models.Event) string { return e.TraceID }},
+	{"timestamp", "Timestamp", func(e models.Event) string { return e.Timestamp }},
+	{"device", "Device Name", func(e models.Event) string { return e.DeviceName }},
+	{"serial", "Serial Number", func(e models.Event) string { return e.SerialNumber }},
+	{"admin", "Admin Name", func(e models.Event) string { return e.AdminName }},
+	{"period", "Period", func(e models.Event) string { return e.Period }},
+	{"category", "Category", func(e models.Event) string { return e.Category }},
+	{"bird", "Bird Name", func(e models.Event) string { return e.BirdName }},
+	{"birdLatin", "Bird Latin", func(e models.Event) string { return e.BirdLatin }},
+	{"confidence", "Confidence", func(e models.Event) string { return formatConfidence(e.BirdConfidence) }},
+	{"detections", "Detections", func(e models.Event) string { return joinDetections(e.Detections) }},
+	{"keyshotUrl", "KeyShot URL", func(e models.Event) string { return e.KeyShotURL }},
+	{"imageUrl", "Image URL", func(e models.Event) string { return e.ImageURL }},
+	{"videoUrl", "Video URL", func(e models.Event) string { return e.VideoURL }},
+}
+
+// DeviceColumns lists every column available for devices, in default output order.
+var DeviceColumns = []DeviceColumn{
+	{"serial", "Serial Number", func(d models.Device) string { return d.SerialNumber }},
+	{"model", "Model", func(d models.Device) string { return d.ModelNo }},
+	{"name", "Name", func(d models.Device) string { return d.DeviceName }},
+	{"network", "Network", func(d models.Device) string { return d.NetworkName }},
+	{"ip", "IP", func(d models.Device) string { return d.IP }},
+	{"battery", "Battery %", func(d models.Device) string { return strconv.Itoa(d.BatteryLevel) }},
+	{"location", "Location", func(d models.Device) string { return d.LocationName }},
+	{"signal", "Signal", func(d models.Device) string { return strconv.Itoa(d.SignalStrength) }},
+	{"wifiChannel", "WiFi Channel", func(d models.Device) string { return strconv.Itoa(d.WifiChannel) }},
+	{"charging", "Charging", func(d models.Device) string { return strconv.FormatBool(d.IsCharging > 0) }},
+	{"chargingMode", "Charging Mode", func(d models.Device) string { return strconv.Itoa(d.ChargingMode) }},
+	{"mac", "MAC Address", func(d models.Device) string { return d.MacAddress }},
+}
+
+// Columns shown in tables when no columns are selected.
+var (
+	DefaultEventTableColumns  = []string{"traceId", "timestamp", "device", "category", "bird", "birdLatin"}
+	DefaultDeviceTableColumns = []string{"serial", "model", "name", "network", "ip", "battery"}
+)
+
+// SelectEventColumns returns the event columns with the given names, in the given order.
+// Names are matched case-insensitively. All columns are returned if names is empty.
+//
+// Parameters:
+//   - names: The column names to select
+//
+// Returns:
+//   - []EventColumn: The selected columns
+//   - error: An error naming the first unknown column
+func SelectEventColumns(names []string) ([]EventColumn, error) {
+	if len(names) == 0 {
+		return EventColumns, nil
+	}
+
+	selected := make([]EventColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range EventColumns {
+			if strings.EqualFold(column.Name, name) {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown event column %q (available: %s)", name, strings.Join(eventColumnNames(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// SelectDeviceColumns returns the device columns with the given names, in the given order.
+// Names are matched case-insensitively. All columns are returned if names is empty.
+//
+// Parameters:
+//   - names: The column names to select
+//
+// Returns:
+//   - []DeviceColumn: The selected columns
+//   - error: An error naming the first unknown column
+func SelectDeviceColumns(names []string) ([]DeviceColumn, error) {
+	if len(names) == 0 {
+		return DeviceColumns, nil
+	}
+
+	selected := make([]DeviceColumn, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range DeviceColumns {
+			if strings.EqualFold(column.Name, name) {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown device column %q (available: %s)", name, strings.Join(deviceColumnNames(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// SortEvents sorts events in place by the value of the named column, in ascending
+// order or in descending order if reverse is set. Numeric values are compared as
+// numbers and other values case-insensitively. If column is empty, the existing
+// order is kept, or reversed if reverse is set.
+//
+// Parameters:
+//   - events: The events to sort
+//   - column: The name of the event column to sort by, or "" for the existing order
+//   - reverse: Whether to sort in descending order
+//
+// Returns:
+//   - error: An error if the column is unknown
+func SortEvents(events []models.Event, column string, reverse bool) error {
+	if column == "" {
+		if reverse {
+			for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+				events[i], events[j] = events[j], events[i]
+			}
+		}
+		return nil
+	}
+
+	columns, err := SelectEventColumns([]string{column})
+	if err != nil {
+		return err
+	}
+	value := columns[0].Value
+	sort.SliceStable(events, func(i, j int) bool {
+		if reverse {
+			return compareValues(value(events[j]), value(events[i])) < 0
+		}
+		return compareValues(value(events[i]), value(events[j])) < 0
+	})
+	return nil
+}
+
+// SortDevices sorts devices in place by the value of the named column, in the same
+// way as SortEvents.
+//
+// Parameters:
+//   - devices: The devices to sort
+//   - column: The name of the device column to sort by, or "" for the existing order
+//   - reverse: Whether to sort in descending order
+//
+// Returns:
+//   - error: An error if the column is unknown
+func SortDevices(devices []models.Device, column string, reverse bool) error {
+	if column == "" {
+		if reverse {
+			for i, j := 0, len(devices)-1; i < j; i, j = i+1, j-1 {
+				devices[i], devices[j] = devices[j], devices[i]
+			}
+		}
+		return nil
+	}
+
+	columns, err := SelectDeviceColumns([]string{column})
+	if err != nil {
+		return err
+	}
+	value := columns[0].Value
+	sort.SliceStable(devices, func(i, j int) bool {
+		if reverse {
+			return compareValues(value(devices[j]), value(devices[i])) < 0
+		}
+		return compareValues(value(devices[i]), value(devices[j])) < 0
+	})
+	return nil
+}
+
+// compareValues compares two column values, numerically if both are numbers.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// eventColumnNames returns the names of all event columns.
+func eventColumnNames() []string {
+	names := make([]string, len(EventColumns))
+	for i, column := range EventColumns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// deviceColumnNames returns the names of all device columns.
+func deviceColumnNames() []string {
+	names := make([]string, len(DeviceColumns))
+	for i, column := range DeviceColumns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// formatConfidence formats a 0-1 confidence score, or returns "" if there is none.
+func formatConfidence(confidence float64) string {
+	if confidence <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(confidence, 'f', -1, 64)
+}
+
+// joinDetections joins the names of all detected subjects with semicolons.
+func joinDetections(detections []models.Detection) string {
+	names := make([]string, 0, len(detections))
+	for _, detection := range detections {
+		if detection.Name != "" {
+			names = append(names, detection.Name)
+		}
+	}
+	return strings.Join(names, ";")
+}
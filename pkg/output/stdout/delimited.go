@@ -0,0 +1,147 @@
+package stdout
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+// DelimitedHandler outputs events and devices as delimiter-separated values with
+// a header row, quoting fields that contain the delimiter, quotes or newlines.
+type DelimitedHandler struct {
+	NoHeaders bool // Omit the header row
+
+	w       *csv.Writer
+	columns []string
+}
+
+// NewCSVHandler creates a handler writing comma-separated values to w, or to stdout if w is nil.
+//
+// Parameters:
+//   - w: The destination for the output
+//   - columns: The names of the columns to write, or nil for all columns
+//
+// Returns:
+//   - *DelimitedHandler: The CSV handler
+//   - error: An error if a column name is unknown
+func NewCSVHandler(w io.Writer, columns []string) (*DelimitedHandler, error) {
+	return newDelimitedHandler(w, ',', columns)
+}
+
+// NewTSVHandler creates a handler writing tab-separated values to w, or to stdout if w is nil.
+//
+// Parameters:
+//   - w: The destination for the output
+//   - columns: The names of the columns to write, or nil for all columns
+//
+// Returns:
+//   - *DelimitedHandler: The TSV handler
+//   - error: An error if a column name is unknown
+func NewTSVHandler(w io.Writer, columns []string) (*DelimitedHandler, error) {
+	return newDelimitedHandler(w, '\t', columns)
+}
+
+// newDelimitedHandler creates a handler using the given field delimiter.
+// The columns must all be event columns or all be device columns.
+func newDelimitedHandler(w io.Writer, delimiter rune, columns []string) (*DelimitedHandler, error) {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if _, err := SelectEventColumns(columns); err != nil {
+		if _, deviceErr := SelectDeviceColumns(columns); deviceErr != nil {
+			return nil, err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	return &DelimitedHandler{w: writer, columns: columns}, nil
+}
+
+// WriteEvents outputs a header row followed by one row per event.
+// The header is written even if there are no events, unless NoHeaders is set.
+func (h *DelimitedHandler) WriteEvents(events []models.Event) error {
+	columns, err := SelectEventColumns(h.columns)
+	if err != nil {
+		return err
+	}
+
+	if !h.NoHeaders {
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		if err := h.w.Write(header); err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+	}
+
+	for _, event := range events {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = column.Value(event)
+		}
+		if err := h.w.Write(record); err != nil {
+			return fmt.Errorf("error writing event %s: %w", event.TraceID, err)
+		}
+	}
+	return h.flush()
+}
+
+// WriteEvent outputs a header row followed by a row for the event.
+func (h *DelimitedHandler) WriteEvent(event models.Event) error {
+	return h.WriteEvents([]models.Event{event})
+}
+
+// WriteDevices outputs a header row followed by one row per device.
+// The header is written even if there are no devices, unless NoHeaders is set.
+func (h *DelimitedHandler) WriteDevices(devices []models.Device) error {
+	columns, err := SelectDeviceColumns(h.columns)
+	if err != nil {
+		return err
+	}
+
+	if !h.NoHeaders {
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		if err := h.w.Write(header); err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+	}
+
+	for _, device := range devices {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = column.Value(device)
+		}
+		if err := h.w.Write(record); err != nil {
+			return fmt.Errorf("error writing device %s: %w", device.SerialNumber, err)
+		}
+	}
+	return h.flush()
+}
+
+// WriteDevice outputs a header row followed by a row for the device.
+func (h *DelimitedHandler) WriteDevice(device models.Device) error {
+	return h.WriteDevices([]models.Device{device})
+}
+
+// Close flushes any buffered output.
+func (h *DelimitedHandler) Close() {
+	h.w.Flush()
+}
+
+// flush writes buffered rows to the underlying writer.
+func (h *DelimitedHandler) flush() error {
+	h.w.Flush()
+	if err := h.w.Error(); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package stdout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+// NDJSONHandler outputs events and devices as newline-delimited JSON, one compact
+// object per line. Nothing is buffered, so each object is written as soon as it is
+// passed to the handler.
+type NDJSONHandler struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONHandler creates a new NDJSON handler writing to w, or to stdout if w is nil.
+func NewNDJSONHandler(w io.Writer) *NDJSONHandler {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &NDJSONHandler{enc: json.NewEncoder(w)}
+}
+
+// WriteEvents outputs one line per event. An empty list writes nothing.
+func (h *NDJSONHandler) WriteEvents(events []models.Event) error {
+	for _, event := range events {
+		if err := h.StreamEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamEvent outputs a single event of a list as soon as it has been fetched.
+func (h *NDJSONHandler) StreamEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteEvent outputs a single event on one line.
+func (h *NDJSONHandler) WriteEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteDevices outputs one line per device. An empty list writes nothing.
+func (h *NDJSONHandler) WriteDevices(devices []models.Device) error {
+	for _, device := range devices {
+		if err := h.write(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDevice outputs a single device on one line.
+func (h *NDJSONHandler) WriteDevice(device models.Device) error {
+	return h.write(device)
+}
+
+// write encodes a value as compact JSON followed by a newline.
+func (h *NDJSONHandler) write(v interface{}) error {
+	if err := h.enc.Encode(v); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
+	return nil
+}
+
+// Close is a no-op for stdout handlers.
+func (h *NDJSONHandler) Close() {
+	// No resources to release
+}
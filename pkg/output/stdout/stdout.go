@@ -1,28 +1,71 @@
-// Package stdout provides implementations for outputting events to standard output.
+// Package stdout provides implementations for outputting events and devices to standard output.
+//
+// Handlers write to the io.Writer they are created with, which is os.Stdout unless
+// another writer is given.
 package stdout
 
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/dydx/vico-cli/pkg/models"
 )
 
-// JSONHandler outputs events in JSON format to stdout.
-type JSONHandler struct{}
+// Default messages shown by the table handler when a list is empty.
+const (
+	DefaultNoEventsMessage  = "No events found in the specified time period."
+	DefaultNoDevicesMessage = "No devices found."
+)
+
+// JSONHandler outputs events and devices in JSON format.
+type JSONHandler struct {
+	w io.Writer
+}
+
+// NewJSONHandler creates a new JSON handler writing to w, or to stdout if w is nil.
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &JSONHandler{w: w}
+}
+
+// WriteEvents outputs the events as a JSON array. An empty list is written as [].
+func (h *JSONHandler) WriteEvents(events []models.Event) error {
+	if events == nil {
+		events = []models.Event{}
+	}
+	return h.write(events)
+}
 
-// NewJSONHandler creates a new JSON stdout handler.
-func NewJSONHandler() *JSONHandler {
-	return &JSONHandler{}
+// WriteEvent outputs a single event as a JSON object.
+func (h *JSONHandler) WriteEvent(event models.Event) error {
+	return h.write(event)
 }
 
-// Write outputs the events in JSON format to stdout.
-func (h *JSONHandler) Write(events []models.Event) error {
-	prettyJSON, err := json.MarshalIndent(events, "", "  ")
+// WriteDevices outputs the devices as a JSON array. An empty list is written as [].
+func (h *JSONHandler) WriteDevices(devices []models.Device) error {
+	if devices == nil {
+		devices = []models.Device{}
+	}
+	return h.write(devices)
+}
+
+// WriteDevice outputs a single device as a JSON object.
+func (h *JSONHandler) WriteDevice(device models.Device) error {
+	return h.write(device)
+}
+
+// write marshals a value as indented JSON.
+func (h *JSONHandler) write(v interface{}) error {
+	prettyJSON, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error formatting JSON: %w", err)
 	}
-	fmt.Println(string(prettyJSON))
+	fmt.Fprintln(h.w, string(prettyJSON))
 	return nil
 }
 
@@ -31,40 +74,257 @@ func (h *JSONHandler) Close() {
 	// No resources to release
 }
 
-// TableHandler outputs events in table format to stdout.
-type TableHandler struct{}
+// TableHandler outputs events and devices in table format. Columns are sized to
+// fit their contents, and if Width is set, the widest columns are shortened so
+// that each line fits in Width cells.
+type TableHandler struct {
+	NoHeaders bool // Omit the header row and rule of list tables
+	Width     int  // Maximum line width in cells, or 0 for no limit
 
-// NewTableHandler creates a new table stdout handler.
-func NewTableHandler() *TableHandler {
-	return &TableHandler{}
+	w            io.Writer
+	emptyMessage string
+	columns      []string
 }
 
-// Write outputs the events in table format to stdout.
-func (h *TableHandler) Write(events []models.Event) error {
+// NewTableHandler creates a new table handler writing to w, or to stdout if w is nil.
+//
+// Parameters:
+//   - w: The destination for the output
+//   - emptyMessage: The message printed instead of an empty table, or "" for a default
+//     message for the kind of list
+//   - columns: The names of the columns of list tables, or nil for the default columns
+//
+// Returns:
+//   - *TableHandler: The table handler
+//   - error: An error if a column name is unknown
+func NewTableHandler(w io.Writer, emptyMessage string, columns []string) (*TableHandler, error) {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	if _, err := SelectEventColumns(columns); err != nil {
+		if _, deviceErr := SelectDeviceColumns(columns); deviceErr != nil {
+			return nil, err
+		}
+	}
+
+	return &TableHandler{w: w, emptyMessage: emptyMessage, columns: columns}, nil
+}
+
+// WriteEvents outputs the events in table format.
+func (h *TableHandler) WriteEvents(events []models.Event) error {
+	names := h.columns
+	if len(names) == 0 {
+		names = DefaultEventTableColumns
+	}
+	columns, err := SelectEventColumns(names)
+	if err != nil {
+		return err
+	}
+
 	if len(events) == 0 {
-		fmt.Println("No events found in the specified time period.")
+		h.writeEmpty(DefaultNoEventsMessage)
 		return nil
 	}
 
-	// Print table header
-	fmt.Printf("%-36s %-20s %-25s %-25s %-25s\n",
-		"Trace ID", "Timestamp", "Device Name", "Bird Name", "Bird Latin")
-	fmt.Println("--------------------------------------------------------------------------------------------------")
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+	rows := make([][]string, len(events))
+	for r, event := range events {
+		rows[r] = make([]string, len(columns))
+		for i, column := range columns {
+			rows[r][i] = column.Value(event)
+		}
+	}
+
+	h.writeTable(headers, rows)
+	return nil
+}
+
+// WriteEvent outputs the details of a single event, one field per line.
+func (h *TableHandler) WriteEvent(event models.Event) error {
+	fmt.Fprintln(h.w, "Event Details:")
+	fmt.Fprintln(h.w, "------------------------------")
+	fmt.Fprintf(h.w, "Trace ID:       %s\n", event.TraceID)
+	fmt.Fprintf(h.w, "Timestamp:      %s\n", event.Timestamp)
+	fmt.Fprintf(h.w, "Device Name:    %s\n", event.DeviceName)
+	fmt.Fprintf(h.w, "Serial Number:  %s\n", event.SerialNumber)
+	fmt.Fprintf(h.w, "Admin Name:     %s\n", event.AdminName)
+	fmt.Fprintf(h.w, "Period:         %s\n", event.Period)
+	fmt.Fprintf(h.w, "Category:       %s\n", event.Category)
+	fmt.Fprintf(h.w, "Bird Name:      %s\n", event.BirdName)
+	fmt.Fprintf(h.w, "Bird Latin:     %s\n", event.BirdLatin)
+	if event.BirdConfidence > 0 {
+		fmt.Fprintf(h.w, "Confidence:     %.2f%%\n", event.BirdConfidence*100)
+	}
+	if len(event.Detections) > 1 {
+		fmt.Fprintf(h.w, "Detections:     %d\n", len(event.Detections))
+		for i, detection := range event.Detections {
+			fmt.Fprintf(h.w, "  [%d] %-9s %s", i+1, detection.ObjectType, detection.Name)
+			if detection.LatinName != "" {
+				fmt.Fprintf(h.w, " (%s)", detection.LatinName)
+			}
+			if detection.Confidence > 0 {
+				fmt.Fprintf(h.w, " %.2f%%", detection.Confidence*100)
+			}
+			fmt.Fprintln(h.w)
+		}
+	}
+	fmt.Fprintf(h.w, "KeyShot URL:    %s\n", event.KeyShotURL)
+	fmt.Fprintf(h.w, "Image URL:      %s\n", event.ImageURL)
+	fmt.Fprintf(h.w, "Video URL:      %s\n", event.VideoURL)
+	if len(event.Keyshots) > 0 {
+		fmt.Fprintf(h.w, "Keyshots:       %d\n", len(event.Keyshots))
+		for i, keyshot := range event.Keyshots {
+			fmt.Fprintf(h.w, "  [%d] Category:  %s\n", i+1, keyshot.ObjectCategory)
+			fmt.Fprintf(h.w, "      Name:      %s\n", keyshot.SubCategoryName)
+			if keyshot.Message != "" {
+				fmt.Fprintf(h.w, "      Message:   %s\n", keyshot.Message)
+			}
+			fmt.Fprintf(h.w, "      Image URL: %s\n", keyshot.ImageURL)
+		}
+	}
+	return nil
+}
+
+// WriteDevices outputs the devices in table format.
+func (h *TableHandler) WriteDevices(devices []models.Device) error {
+	names := h.columns
+	if len(names) == 0 {
+		names = DefaultDeviceTableColumns
+	}
+	columns, err := SelectDeviceColumns(names)
+	if err != nil {
+		return err
+	}
 
-	// Print table rows
-	for _, event := range events {
-		fmt.Printf("%-36s %-20s %-25s %-25s %-25s\n",
-			event.TraceID,
-			event.Timestamp,
-			event.DeviceName,
-			event.BirdName,
-			event.BirdLatin)
+	if len(devices) == 0 {
+		h.writeEmpty(DefaultNoDevicesMessage)
+		return nil
 	}
 
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+	rows := make([][]string, len(devices))
+	for r, device := range devices {
+		rows[r] = make([]string, len(columns))
+		for i, column := range columns {
+			rows[r][i] = column.Value(device)
+		}
+	}
+
+	h.writeTable(headers, rows)
+	return nil
+}
+
+// WriteDevice outputs the details of a single device, one field per line.
+func (h *TableHandler) WriteDevice(device models.Device) error {
+	fmt.Fprintln(h.w, "Device Details:")
+	fmt.Fprintln(h.w, "------------------------------")
+	fmt.Fprintf(h.w, "Serial Number:   %s\n", device.SerialNumber)
+	fmt.Fprintf(h.w, "Model Number:    %s\n", device.ModelNo)
+	fmt.Fprintf(h.w, "Device Name:     %s\n", device.DeviceName)
+	fmt.Fprintf(h.w, "Network Name:    %s\n", device.NetworkName)
+	fmt.Fprintf(h.w, "IP Address:      %s\n", device.IP)
+	fmt.Fprintf(h.w, "Battery Level:   %d%%\n", device.BatteryLevel)
+	fmt.Fprintf(h.w, "Location:        %s\n", device.LocationName)
+	fmt.Fprintf(h.w, "Signal Strength: %d dBm\n", device.SignalStrength)
+	fmt.Fprintf(h.w, "WiFi Channel:    %d\n", device.WifiChannel)
+	fmt.Fprintf(h.w, "Is Charging:     %s\n", boolFromInt(device.IsCharging))
+	fmt.Fprintf(h.w, "Charging Mode:   %d\n", device.ChargingMode)
+	fmt.Fprintf(h.w, "MAC Address:     %s\n", device.MacAddress)
 	return nil
 }
 
+// columnGap is the number of spaces between table columns.
+const columnGap = 2
+
+// minColumnWidth is the narrowest a column is shortened to when fitting a table to Width.
+const minColumnWidth = 6
+
+// writeTable prints a header row, a rule and the rows, with each column as wide as
+// its widest cell. If the table is wider than Width, the widest columns are shortened
+// and cells that no longer fit are truncated.
+func (h *TableHandler) writeTable(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		if !h.NoHeaders {
+			widths[i] = displayWidth(header)
+		}
+		for _, row := range rows {
+			if w := displayWidth(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	total := columnGap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for h.Width > 0 && total > h.Width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	if !h.NoHeaders {
+		h.writeRow(headers, widths)
+		fmt.Fprintln(h.w, strings.Repeat("-", total))
+	}
+	for _, row := range rows {
+		h.writeRow(row, widths)
+	}
+}
+
+// writeRow prints the cells of one row, truncated and padded to the column widths.
+// The last cell is not padded, so lines have no trailing spaces.
+func (h *TableHandler) writeRow(cells []string, widths []int) {
+	var b strings.Builder
+	for i, cell := range cells {
+		cell = truncate(cell, widths[i])
+		if i == len(cells)-1 {
+			b.WriteString(cell)
+			break
+		}
+		b.WriteString(pad(cell, widths[i]))
+		b.WriteString(strings.Repeat(" ", columnGap))
+	}
+	fmt.Fprintln(h.w, strings.TrimRight(b.String(), " "))
+}
+
+// writeEmpty prints the configured empty-result message, or fallback if none is configured.
+func (h *TableHandler) writeEmpty(fallback string) {
+	if h.emptyMessage != "" {
+		fmt.Fprintln(h.w, h.emptyMessage)
+		return
+	}
+	fmt.Fprintln(h.w, fallback)
+}
+
 // Close is a no-op for stdout handlers.
 func (h *TableHandler) Close() {
 	// No resources to release
 }
+
+// boolFromInt converts an integer value to a human-readable string representation
+// of a boolean value. Any value greater than 0 returns "Yes", otherwise "No".
+// This is used for display purposes when showing boolean properties from the API.
+func boolFromInt(val int) string {
+	if val > 0 {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,198 @@
+package stdout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/dydx/vico-cli/pkg/models"
+	"github.com/dydx/vico-cli/pkg/output/jsonpath"
+)
+
+// TemplateHandler outputs each event or device through a Go template, such as
+// "{{.TraceID}} {{.BirdName}}". The template is applied to every item of a list
+// separately, using the Go field names of models.Event and models.Device.
+type TemplateHandler struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+// NewTemplateHandler creates a new Go template handler writing to w, or to stdout if w is nil.
+//
+// Parameters:
+//   - w: The destination for the output
+//   - text: The template applied to each event or device
+//
+// Returns:
+//   - *TemplateHandler: The template handler
+//   - error: An error if the template is empty or invalid
+func NewTemplateHandler(w io.Writer, text string) (*TemplateHandler, error) {
+	if text == "" {
+		return nil, fmt.Errorf("template output requires a template (use --template)")
+	}
+	if w == nil {
+		w = os.Stdout
+	}
+
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+	return &TemplateHandler{w: w, tmpl: tmpl}, nil
+}
+
+// WriteEvents applies the template to each event.
+func (h *TemplateHandler) WriteEvents(events []models.Event) error {
+	for _, event := range events {
+		if err := h.StreamEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamEvent applies the template to a single event of a list as soon as it has been fetched.
+func (h *TemplateHandler) StreamEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteEvent applies the template to a single event.
+func (h *TemplateHandler) WriteEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteDevices applies the template to each device.
+func (h *TemplateHandler) WriteDevices(devices []models.Device) error {
+	for _, device := range devices {
+		if err := h.write(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDevice applies the template to a single device.
+func (h *TemplateHandler) WriteDevice(device models.Device) error {
+	return h.write(device)
+}
+
+// write executes the template for one item.
+func (h *TemplateHandler) write(v interface{}) error {
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, v); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+	return writeLine(h.w, buf.Bytes())
+}
+
+// Close is a no-op for stdout handlers.
+func (h *TemplateHandler) Close() {
+	// No resources to release
+}
+
+// JSONPathHandler outputs fields of each event or device selected by a JSONPath
+// template, such as "{.serialNumber}". The template is applied to every item of a
+// list separately, using the JSON field names of models.Event and models.Device.
+type JSONPathHandler struct {
+	w    io.Writer
+	tmpl *jsonpath.Template
+}
+
+// NewJSONPathHandler creates a new JSONPath handler writing to w, or to stdout if w is nil.
+//
+// Parameters:
+//   - w: The destination for the output
+//   - text: The JSONPath template applied to each event or device
+//
+// Returns:
+//   - *JSONPathHandler: The JSONPath handler
+//   - error: An error if the template is empty or invalid
+func NewJSONPathHandler(w io.Writer, text string) (*JSONPathHandler, error) {
+	if text == "" {
+		return nil, fmt.Errorf("jsonpath output requires a template (use --format jsonpath='{.field}')")
+	}
+	if w == nil {
+		w = os.Stdout
+	}
+
+	tmpl, err := jsonpath.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPathHandler{w: w, tmpl: tmpl}, nil
+}
+
+// WriteEvents applies the template to each event.
+func (h *JSONPathHandler) WriteEvents(events []models.Event) error {
+	for _, event := range events {
+		if err := h.StreamEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamEvent applies the template to a single event of a list as soon as it has been fetched.
+func (h *JSONPathHandler) StreamEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteEvent applies the template to a single event.
+func (h *JSONPathHandler) WriteEvent(event models.Event) error {
+	return h.write(event)
+}
+
+// WriteDevices applies the template to each device.
+func (h *JSONPathHandler) WriteDevices(devices []models.Device) error {
+	for _, device := range devices {
+		if err := h.write(device); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDevice applies the template to a single device.
+func (h *JSONPathHandler) WriteDevice(device models.Device) error {
+	return h.write(device)
+}
+
+// write evaluates the template against the JSON representation of one item.
+func (h *JSONPathHandler) write(v interface{}) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error formatting JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return fmt.Errorf("error formatting JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing JSONPath template: %w", err)
+	}
+	return writeLine(h.w, buf.Bytes())
+}
+
+// Close is a no-op for stdout handlers.
+func (h *JSONPathHandler) Close() {
+	// No resources to release
+}
+
+// writeLine writes the output for one item, adding a newline if it does not end with one.
+func writeLine(w io.Writer, line []byte) error {
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+	if _, err := w.Write(line); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+	return nil
+}
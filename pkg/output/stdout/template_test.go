@@ -0,0 +1,105 @@
+package stdout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dydx/vico-cli/pkg/models"
+)
+
+var testEvents = []models.Event{
+	{TraceID: "trace-1", DeviceName: "Feeder", BirdName: "Robin", BirdConfidence: 0.95},
+	{TraceID: "trace-2", DeviceName: "Porch", Detections: []models.Detection{{ObjectType: "person"}}},
+}
+
+func TestTemplateHandler(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{{.TraceID}} {{.BirdName}}", "trace-1 Robin\ntrace-2 \n"},
+		{"{{.DeviceName}}\n", "Feeder\nPorch\n"},
+		{`{{if .BirdName}}{{.BirdName}} {{printf "%.0f" .BirdConfidence}}{{else}}no bird{{end}}`, "Robin 1\nno bird\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler, err := NewTemplateHandler(&buf, tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := handler.WriteEvents(testEvents); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateHandlerErrors(t *testing.T) {
+	if _, err := NewTemplateHandler(nil, ""); err == nil {
+		t.Error("NewTemplateHandler accepted an empty template")
+	}
+	if _, err := NewTemplateHandler(nil, "{{.TraceID"); err == nil {
+		t.Error("NewTemplateHandler accepted an unterminated action")
+	}
+
+	handler, err := NewTemplateHandler(&bytes.Buffer{}, "{{.NoSuchField}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.WriteDevice(models.Device{}); err == nil || !strings.Contains(err.Error(), "NoSuchField") {
+		t.Errorf("WriteDevice error = %v, want an error naming the unknown field", err)
+	}
+}
+
+func TestJSONPathHandler(t *testing.T) {
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.traceId}", "trace-1\ntrace-2\n"},
+		{`{.deviceName}{"\t"}{.birdConfidence}`, "Feeder\t0.95\nPorch\t0\n"},
+		{"{.detections[*].objectType}", "\nperson\n"},
+		{"{.missing}", "\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler, err := NewJSONPathHandler(&buf, tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := handler.WriteEvents(testEvents); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	handler, err := NewJSONPathHandler(&buf, "{.serialNumber} {.batteryLevel}%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.WriteDevice(models.Device{SerialNumber: "SN1", BatteryLevel: 85}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "SN1 85%\n"; buf.String() != want {
+		t.Errorf("device output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONPathHandlerErrors(t *testing.T) {
+	if _, err := NewJSONPathHandler(nil, ""); err == nil {
+		t.Error("NewJSONPathHandler accepted an empty template")
+	}
+	if _, err := NewJSONPathHandler(nil, "{.traceId"); err == nil {
+		t.Error("NewJSONPathHandler accepted an unclosed expression")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package stdout
+
+import "os"
+
+// terminalWidth is not supported on this platform, so tables are only limited by COLUMNS.
+func terminalWidth(f *os.File) int {
+	return 0
+}
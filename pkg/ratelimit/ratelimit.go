@@ -0,0 +1,94 @@
+// Package ratelimit limits how fast requests are sent.
+//
+// A Limiter is a token bucket: it holds up to a burst of tokens, refills at a steady
+// rate, and every request takes one token, waiting for it if the bucket is empty.
+// Bulk operations such as long history pulls and mass downloads share a limiter, so
+// that they stay below the rate at which the API starts to throttle clients.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket rate limiter. All methods are safe for concurrent use,
+// and a nil *Limiter does not limit at all.
+type Limiter struct {
+	rate  float64 // Tokens added per second
+	burst float64 // Capacity of the bucket
+
+	mu     sync.Mutex
+	tokens float64   // Tokens in the bucket; negative while requests wait for tokens
+	last   time.Time // When tokens was last brought up to date
+}
+
+// New creates a Limiter allowing rate requests per second on average, with bursts of
+// up to burst requests. A burst below 1 is raised to 1. New returns nil, which does
+// not limit, if rate is not positive.
+//
+// Parameters:
+//   - rate: Requests per second
+//   - burst: Requests that may be sent at once before the rate applies
+//
+// Returns:
+//   - *Limiter: The limiter, starting with a full bucket
+func New(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done. If ctx is done first, the
+// token taken for the request is returned to the bucket and ctx's error is returned.
+func (l *Limiter) Wait(ctx context.Context) error {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait
+// before using it. Tokens are taken even when the bucket is empty, so that waiting
+// callers are served in order.
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewWithoutRate(t *testing.T) {
+	l := New(0, 5)
+	if l != nil {
+		t.Fatalf("New(0, 5) = %+v, want nil", l)
+	}
+	// A nil limiter never waits
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait on a nil limiter = %v", err)
+		}
+	}
+}
+
+func TestReserve(t *testing.T) {
+	l := New(2, 3)
+	now := l.last
+
+	// The full bucket allows a burst without waiting
+	for i := 0; i < 3; i++ {
+		if delay := l.reserve(now); delay != 0 {
+			t.Fatalf("request %d of the burst waits %s, want 0", i+1, delay)
+		}
+	}
+
+	// Further requests queue up behind each other at the rate
+	for i, want := range []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond} {
+		if delay := l.reserve(now); delay != want {
+			t.Errorf("request %d after the burst waits %s, want %s", i+1, delay, want)
+		}
+	}
+
+	// The bucket refills at the rate, but never beyond the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if delay := l.reserve(now); delay != 0 {
+			t.Fatalf("request %d after refilling waits %s, want 0", i+1, delay)
+		}
+	}
+	if delay := l.reserve(now); delay != 500*time.Millisecond {
+		t.Errorf("request beyond the refilled burst waits %s, want 500ms", delay)
+	}
+}
+
+func TestNewRaisesBurst(t *testing.T) {
+	l := New(1, 0)
+	if delay := l.reserve(l.last); delay != 0 {
+		t.Errorf("first request waits %s, want 0", delay)
+	}
+	if delay := l.reserve(l.last); delay != time.Second {
+		t.Errorf("second request waits %s, want 1s", delay)
+	}
+}
+
+func TestWait(t *testing.T) {
+	l := New(50, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Two requests beyond the burst wait 20ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("3 requests at 50/s with a burst of 1 took %s, want about 40ms", elapsed)
+	}
+}
+
+func TestWaitCanceled(t *testing.T) {
+	l := New(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The canceled request returned its token, so the next one is not pushed back
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("bucket holds %.2f tokens after the canceled wait, want the token returned", tokens)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New(1, 1).Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait with a canceled context = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,425 @@
+// Package recorder captures HTTP exchanges with the Vicohome API and serves them back.
+//
+// A Recorder is an http.RoundTripper that passes requests on and saves each request
+// and response pair as a JSON file in a directory. Credentials are redacted before
+// anything is written: authorization headers and cookies, every JSON string whose
+// key names a token, password or email address, and the query parameter values of
+// the request URL and of every URL in a JSON body, since signed media URLs carry
+// credentials in their query string. A Replayer answers requests from such
+// a directory without network access, which turns a captured session into a
+// reproducible fixture for parsing bugs.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive values in recorded exchanges.
+const Redacted = "REDACTED"
+
+// Exchange is a recorded request and its response, as stored in one file.
+type Exchange struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of an HTTP request.
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"` // JSON bodies
+	Text   string          `json:"text,omitempty"` // Other bodies
+}
+
+// Response is the recorded part of an HTTP response.
+type Response struct {
+	StatusCode int             `json:"statusCode"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"` // JSON bodies
+	Text       string          `json:"text,omitempty"` // Other bodies
+}
+
+// sensitiveHeaders are replaced by Redacted in recorded exchanges.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Recorder is an http.RoundTripper that saves every exchange it passes on.
+// It is safe for concurrent use.
+type Recorder struct {
+	Dir  string            // Directory the exchanges are written to
+	Next http.RoundTripper // Transport that sends the requests
+
+	mu   sync.Mutex
+	next int // Number of the next exchange file
+}
+
+// NewRecorder creates a Recorder that writes into dir, creating it if needed.
+// Numbering continues after the highest numbered exchange already in dir, so that
+// several commands can be recorded into the same directory.
+//
+// Parameters:
+//   - dir: The directory to write exchanges to
+//   - next: The transport that sends the requests; nil selects http.DefaultTransport
+//
+// Returns:
+//   - *Recorder: The recorder
+//   - error: An error if the directory cannot be created or read
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating recording directory: %w", err)
+	}
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	last := 0
+	for _, file := range files {
+		if n, ok := sequenceNumber(file); ok && n > last {
+			last = n
+		}
+	}
+	return &Recorder{Dir: dir, Next: next, next: last + 1}, nil
+}
+
+// sequenceNumber returns the number an exchange file name starts with.
+func sequenceNumber(file string) (int, bool) {
+	prefix, _, ok := strings.Cut(filepath.Base(file), "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(prefix)
+	return n, err == nil
+}
+
+// RoundTrip sends the request through Next and records the exchange. The response
+// body is read completely and handed back to the caller unchanged. Failing to write
+// the recording fails the request, so that a recording is never silently incomplete.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	exchange := Exchange{
+		Request: Request{
+			Method: req.Method,
+			URL:    redactQuery(req.URL.String()),
+			Header: sanitizeHeader(req.Header),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     sanitizeHeader(resp.Header),
+		},
+	}
+	exchange.Request.Body, exchange.Request.Text = sanitizeBody(reqBody)
+	exchange.Response.Body, exchange.Response.Text = sanitizeBody(respBody)
+
+	if err := r.save(req, exchange); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes an exchange to the next numbered file, named after the request.
+// Existing files are never overwritten; writing fails if the file already exists.
+func (r *Recorder) save(req *http.Request, exchange Exchange) error {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding exchange: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := fmt.Sprintf("%04d-%s%s.json", r.next, req.Method, fileSafe.ReplaceAllString(req.URL.Path, "-"))
+	file, err := os.OpenFile(filepath.Join(r.Dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating exchange file: %w", err)
+	}
+	r.next++
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing exchange: %w", err)
+	}
+	return nil
+}
+
+// fileSafe matches the characters of a URL path that are replaced in file names.
+var fileSafe = regexp.MustCompile(`[^A-Za-z0-9._]+`)
+
+// Replayer is an http.RoundTripper that answers requests with recorded responses
+// instead of sending them. It is safe for concurrent use.
+//
+// A request is answered by the first unused exchange with the same method and URL
+// path whose sanitized body is identical; failing that, by the first unused one with
+// the same method and path, since bodies often hold timestamps that change between
+// runs. Once all matching exchanges have been used, the last one is repeated.
+// The host of the URL is ignored, so a recording can be replayed against any base URL.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+	last      map[string]int // method and path -> index of the exchange last served
+}
+
+// NewReplayer loads the exchanges recorded in dir.
+//
+// Parameters:
+//   - dir: A directory written by a Recorder
+//
+// Returns:
+//   - *Replayer: The replayer
+//   - error: An error if the directory holds no exchanges or one cannot be read
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := exchangeFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges in %s", dir)
+	}
+
+	r := &Replayer{last: make(map[string]int)}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading exchange: %w", err)
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return nil, fmt.Errorf("error parsing exchange %s: %w", filepath.Base(file), err)
+		}
+		r.exchanges = append(r.exchanges, exchange)
+	}
+	r.used = make([]bool, len(r.exchanges))
+	return r, nil
+}
+
+// RoundTrip returns the recorded response for the request, or an error if none of the
+// exchanges matches its method and path.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	body, text := sanitizeBody(reqBody)
+
+	exchange, ok := r.match(req.Method, req.URL.Path, body, text)
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL.Path)
+	}
+
+	respBody := []byte(exchange.Response.Text)
+	if exchange.Response.Body != nil {
+		respBody = exchange.Response.Body
+	}
+	header := exchange.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Response.StatusCode, http.StatusText(exchange.Response.StatusCode)),
+		StatusCode:    exchange.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// match selects the exchange that answers a request, see Replayer.
+func (r *Replayer) match(method, path string, body json.RawMessage, text string) (Exchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := method + " " + path
+	found := -1
+	for i, exchange := range r.exchanges {
+		if r.used[i] || !r.matches(exchange, method, path) {
+			continue
+		}
+		if bytes.Equal(compact(exchange.Request.Body), compact(body)) && exchange.Request.Text == text {
+			found = i
+			break
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		last, ok := r.last[key]
+		if !ok {
+			return Exchange{}, false
+		}
+		return r.exchanges[last], true
+	}
+
+	r.used[found] = true
+	r.last[key] = found
+	return r.exchanges[found], true
+}
+
+// matches reports whether an exchange was recorded for the given method and path.
+func (r *Replayer) matches(exchange Exchange, method, path string) bool {
+	if exchange.Request.Method != method {
+		return false
+	}
+	recorded, err := url.Parse(exchange.Request.URL)
+	return err == nil && recorded.Path == path
+}
+
+// exchangeFiles returns the exchange files in dir in recording order.
+func exchangeFiles(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("error reading recording directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing exchanges: %w", err)
+	}
+	return files, nil
+}
+
+// sanitizeHeader returns a copy of header with credentials redacted.
+func sanitizeHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	sanitized := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if sanitized.Get(name) != "" {
+			sanitized.Set(name, Redacted)
+		}
+	}
+	return sanitized
+}
+
+// sanitizeBody redacts the credentials in a body. JSON bodies are returned as JSON
+// with sensitive string values replaced, other bodies as text.
+func sanitizeBody(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, string(body)
+	}
+	sanitized, err := json.Marshal(redact(value))
+	if err != nil {
+		return nil, string(body)
+	}
+	return sanitized, ""
+}
+
+// redact replaces the string values of sensitive keys throughout a decoded JSON value,
+// and the query parameter values of URLs, see redactQuery. Objects under sensitive
+// keys are searched rather than replaced, so that the structure of a login response
+// is kept.
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := field.(string); ok && isSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redact(field)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+		return v
+	case string:
+		return redactQuery(v)
+	default:
+		return v
+	}
+}
+
+// isSensitive reports whether a JSON key names a token, password or email address.
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "token") || strings.Contains(key, "password") || strings.Contains(key, "email")
+}
+
+// redactQuery replaces the query parameter values of an absolute http(s) URL with
+// Redacted, keeping their names. Other strings are returned unchanged.
+func redactQuery(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery == "" {
+		return s
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		// Keep nothing of a query that cannot be taken apart
+		u.RawQuery = Redacted
+		return u.String()
+	}
+	for name, values := range query {
+		for i := range values {
+			values[i] = Redacted
+		}
+		query[name] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// compact removes insignificant whitespace from a JSON body for comparison.
+func compact(body json.RawMessage) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, body); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,194 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "credentials",
+			body: `{"email":"me@example.com","password":"hunter2","loginType":0}`,
+			want: `{"email":"REDACTED","loginType":0,"password":"REDACTED"}`,
+		},
+		{
+			name: "nested token",
+			body: `{"data":{"token":{"token":"abc","expiresIn":3600}}}`,
+			want: `{"data":{"token":{"expiresIn":3600,"token":"REDACTED"}}}`,
+		},
+		{
+			name: "signed URL",
+			body: `{"imageUrl":"https://cdn.example.com/a.jpg?X-Amz-Signature=secret&X-Amz-Expires=60"}`,
+			want: `{"imageUrl":"https://cdn.example.com/a.jpg?X-Amz-Expires=REDACTED\u0026X-Amz-Signature=REDACTED"}`,
+		},
+		{
+			name: "URLs in lists",
+			body: `{"list":[{"videoUrl":"https://cdn.example.com/v.m3u8?sig=1"},"plain text"]}`,
+			want: `{"list":[{"videoUrl":"https://cdn.example.com/v.m3u8?sig=REDACTED"},"plain text"]}`,
+		},
+		{
+			name: "URL without query",
+			body: `{"imageUrl":"https://cdn.example.com/a.jpg","count":2}`,
+			want: `{"count":2,"imageUrl":"https://cdn.example.com/a.jpg"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, text := sanitizeBody([]byte(tt.body))
+			if text != "" {
+				t.Fatalf("sanitizeBody returned text %q for a JSON body", text)
+			}
+			if string(body) != tt.want {
+				t.Errorf("sanitizeBody = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeBodyText(t *testing.T) {
+	body, text := sanitizeBody([]byte("<html>bad gateway</html>"))
+	if body != nil || text != "<html>bad gateway</html>" {
+		t.Errorf("sanitizeBody = %q, %q, want the text unchanged", body, text)
+	}
+}
+
+func TestSanitizeHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "secret")
+	header.Set("Content-Type", "application/json")
+
+	sanitized := sanitizeHeader(header)
+	if got := sanitized.Get("Authorization"); got != Redacted {
+		t.Errorf("Authorization = %q, want %q", got, Redacted)
+	}
+	if got := sanitized.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want it unchanged", got)
+	}
+	if got := header.Get("Authorization"); got != "secret" {
+		t.Errorf("original header was modified: Authorization = %q", got)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"result":0,"data":{"imageUrl":"https://cdn.example.com/a.jpg?signature=secret"}}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec}
+
+	req, _ := http.NewRequest("POST", server.URL+"/library/list?sign=secret", strings.NewReader(`{"password":"hunter2"}`))
+	req.Header.Set("Authorization", "secret-token")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(live), "signature=secret") {
+		t.Fatalf("recorder changed the response seen by the caller: %s", live)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret", "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("recording contains %q:\n%s", secret, data)
+		}
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ = http.NewRequest("POST", "http://replay.invalid/library/list", strings.NewReader(`{"password":"other"}`))
+	resp, err = replayer.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var replayed struct {
+		Result int `json:"result"`
+		Data   struct {
+			ImageURL string `json:"imageUrl"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&replayed); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://cdn.example.com/a.jpg?signature=REDACTED"; replayed.Data.ImageURL != want {
+		t.Errorf("replayed imageUrl = %q, want %q", replayed.Data.ImageURL, want)
+	}
+
+	req, _ = http.NewRequest("POST", "http://replay.invalid/device/list", nil)
+	if _, err := replayer.RoundTrip(req); err == nil {
+		t.Error("replaying an unrecorded path succeeded, want an error")
+	}
+}
+
+func TestRecorderNumbering(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"result":0}`)
+	}))
+	defer server.Close()
+
+	// Recordings with a gap in their numbering, as left by deleting one
+	dir := t.TempDir()
+	for _, name := range []string{"0001-POST-a.json", "0003-POST-b.json", "notes.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rec}).Post(server.URL+"/c", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "0004-POST-c.json")); err != nil {
+		t.Errorf("exchange was not numbered after the highest existing one: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "0003-POST-b.json")); string(data) != "{}\n" {
+		t.Errorf("existing exchange was overwritten: %s", data)
+	}
+
+	// A file created under the next number in the meantime is not overwritten
+	if err := os.WriteFile(filepath.Join(dir, "0005-POST-c.json"), []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&http.Client{Transport: rec}).Post(server.URL+"/c", "application/json", nil); err == nil {
+		t.Error("recording over an existing exchange succeeded, want an error")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "0005-POST-c.json")); string(data) != "{}\n" {
+		t.Errorf("existing exchange was overwritten: %s", data)
+	}
+}
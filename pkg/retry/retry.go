@@ -0,0 +1,207 @@
+// Package retry resends HTTP requests that failed for transient reasons.
+//
+// A Policy decides how often a request is attempted and how long to wait between
+// attempts: the delay grows exponentially with random jitter, and a Retry-After
+// header sent with a throttling or unavailable response is honored. Only transient
+// failures are retried: the status codes listed by the policy, timeouts and
+// connections that were reset or closed by the server. Nothing is retried once the
+// request's context is done.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/dydx/vico-cli/pkg/logging"
+)
+
+// Policy controls how requests are retried.
+// The zero value attempts each request once.
+type Policy struct {
+	MaxAttempts     int           // Attempts per request, including the first; 1 or less disables retries
+	InitialBackoff  time.Duration // Delay before the first retry
+	MaxBackoff      time.Duration // Upper bound on the delay between attempts
+	Multiplier      float64       // Factor the delay grows by after each retry
+	Jitter          float64       // Fraction of each delay that is randomized, from 0 to 1
+	MaxRetryAfter   time.Duration // Longest Retry-After delay that is waited for; longer ones end the retries
+	RetryableStatus []int         // HTTP status codes that are retried
+}
+
+// DefaultRetryableStatus lists the HTTP status codes retried by DefaultPolicy:
+// request timeouts, throttling and temporary server or gateway failures.
+var DefaultRetryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultPolicy returns the policy used by the API client and the downloader:
+// up to 4 attempts, waiting at most 0.5s, 1s and 2s between them.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:     4,
+		InitialBackoff:  500 * time.Millisecond,
+		MaxBackoff:      30 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.5,
+		MaxRetryAfter:   2 * time.Minute,
+		RetryableStatus: DefaultRetryableStatus,
+	}
+}
+
+// Do calls send until it returns a response that is not retryable, a non-transient
+// error, or the policy's attempts are used up. Every attempt must send a fresh
+// request, since the body of the previous one has been consumed. Waiting between
+// attempts ends early when ctx is done, and ctx's error is returned.
+//
+// The bodies of retried responses are drained and closed. When the attempts are
+// used up, the last response or error is returned, so a response with a retryable
+// status can still be returned and must be checked by the caller.
+//
+// Parameters:
+//   - ctx: The context of the request
+//   - send: Sends one attempt of the request
+//
+// Returns:
+//   - *http.Response: The final response, if one was received
+//   - error: The final transport error, if no response was received
+func (p Policy) Do(ctx context.Context, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+
+		if err == nil && !p.Retryable(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && (ctx.Err() != nil || !IsTransient(err)) {
+			return nil, err
+		}
+		if attempt >= p.MaxAttempts {
+			return resp, err
+		}
+
+		delay := p.Backoff(attempt)
+		if resp != nil {
+			if after, ok := RetryAfter(resp.Header, time.Now()); ok {
+				if p.MaxRetryAfter > 0 && after > p.MaxRetryAfter {
+					// The server asks for a longer pause than we are willing to wait
+					return resp, nil
+				}
+				if after > delay {
+					delay = after
+				}
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		attrs := []any{"attempt", attempt, "delay_ms", delay.Milliseconds()}
+		if resp != nil {
+			attrs = append(attrs, "status", resp.StatusCode)
+		} else {
+			attrs = append(attrs, logging.Error(err))
+		}
+		logging.Logger().InfoContext(ctx, "retrying request", attrs...)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Retryable reports whether a response with the given HTTP status code is retried.
+func (p Policy) Retryable(status int) bool {
+	for _, code := range p.RetryableStatus {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the given retry, counting from 1. The delay
+// starts at InitialBackoff and is multiplied by Multiplier for each further retry,
+// up to MaxBackoff. A random share of up to Jitter of the delay is then taken off,
+// so that clients that failed together do not retry in lockstep.
+func (p Policy) Backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// RetryAfter parses a Retry-After header, which holds either a number of seconds or
+// an HTTP date, into the delay it asks for.
+//
+// Parameters:
+//   - header: The response headers
+//   - now: The current time, against which an HTTP date is measured
+//
+// Returns:
+//   - time.Duration: The requested delay; dates in the past yield 0
+//   - bool: False if the header is missing or malformed
+func RetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// IsTransient reports whether a transport error is likely to go away when the request
+// is repeated: timeouts, and connections reset, aborted or closed by the server.
+// Other errors, such as unknown hosts or refused connections, point at a
+// configuration problem and are not retried.
+func IsTransient(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	default:
+		return false
+	}
+}
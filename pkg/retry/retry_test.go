@@ -0,0 +1,232 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{20, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.Backoff(tt.retry); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.retry, got, tt.want)
+		}
+	}
+
+	// A multiplier below 1 keeps the delay constant
+	if got := (Policy{InitialBackoff: time.Second}).Backoff(3); got != time.Second {
+		t.Errorf("Backoff without a multiplier = %s, want 1s", got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	p := Policy{InitialBackoff: time.Second, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := p.Backoff(2); got < time.Second || got > 2*time.Second {
+			t.Fatalf("Backoff(2) with jitter = %s, want between 1s and 2s", got)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "missing"},
+		{name: "seconds", value: "30", want: 30 * time.Second, wantOK: true},
+		{name: "zero", value: "0", wantOK: true},
+		{name: "negative", value: "-1"},
+		{name: "date", value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{name: "past date", value: now.Add(-time.Hour).Format(http.TimeFormat), wantOK: true},
+		{name: "malformed", value: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			got, ok := RetryAfter(header, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "timeout", err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, want: true},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: true},
+		{name: "broken pipe", err: fmt.Errorf("write: %w", syscall.EPIPE), want: true},
+		{name: "closed by server", err: fmt.Errorf("Post: %w", io.EOF), want: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+		{name: "unknown host", err: &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true}},
+		{name: "canceled", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%s) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testPolicy retries quickly so that tests do not wait.
+var testPolicy = Policy{
+	MaxAttempts:     3,
+	InitialBackoff:  time.Millisecond,
+	Multiplier:      2,
+	MaxRetryAfter:   time.Second,
+	RetryableStatus: DefaultRetryableStatus,
+}
+
+// statusServer answers the requests it receives with the given statuses in turn,
+// repeating the last one, and counts the requests.
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[min(requests, len(statuses)-1)]
+		requests++
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "status %d", status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       http.Header
+		statuses     []int
+		wantStatus   int
+		wantRequests int
+	}{
+		{name: "success", statuses: []int{200}, wantStatus: 200, wantRequests: 1},
+		{name: "retried until success", statuses: []int{503, 502, 200}, wantStatus: 200, wantRequests: 3},
+		{name: "attempts used up", statuses: []int{500}, wantStatus: 500, wantRequests: 3},
+		{name: "not retryable", statuses: []int{404, 200}, wantStatus: 404, wantRequests: 1},
+		{name: "short Retry-After", header: http.Header{"Retry-After": {"0"}}, statuses: []int{429, 200}, wantStatus: 200, wantRequests: 2},
+		{name: "long Retry-After", header: http.Header{"Retry-After": {"3600"}}, statuses: []int{429, 200}, wantStatus: 429, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := statusServer(t, tt.header, tt.statuses...)
+			resp, err := testPolicy.Do(context.Background(), func() (*http.Response, error) {
+				return http.Get(server.URL)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if want := fmt.Sprintf("status %d", tt.wantStatus); string(body) != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+			if *requests != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", *requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDoErrors(t *testing.T) {
+	reset := &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	refused := &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantSent int
+	}{
+		{name: "transient", err: reset, wantSent: 3},
+		{name: "permanent", err: refused, wantSent: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := 0
+			resp, err := testPolicy.Do(context.Background(), func() (*http.Response, error) {
+				sent++
+				return nil, tt.err
+			})
+			if resp != nil || !errors.Is(err, tt.err) {
+				t.Errorf("Do = %v, %v, want the send error", resp, err)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent %d attempts, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	server, requests := statusServer(t, nil, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	p := testPolicy
+	p.InitialBackoff = time.Hour
+	start := time.Now()
+	resp, err := p.Do(ctx, func() (*http.Response, error) {
+		return http.Get(server.URL)
+	})
+	if resp != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do = %v, %v, want context.DeadlineExceeded", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Do returned after %s, want it to stop waiting when the context is done", elapsed)
+	}
+	if *requests != 1 {
+		t.Errorf("sent %d requests, want 1", *requests)
+	}
+}
+
+func TestZeroPolicyAttemptsOnce(t *testing.T) {
+	server, requests := statusServer(t, nil, http.StatusServiceUnavailable)
+	resp, err := Policy{RetryableStatus: DefaultRetryableStatus}.Do(context.Background(), func() (*http.Response, error) {
+		return http.Get(server.URL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || *requests != 1 {
+		t.Errorf("Do = status %d after %d requests, want 503 after 1", resp.StatusCode, *requests)
+	}
+}
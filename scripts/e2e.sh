@@ -0,0 +1,116 @@
+#!/bin/bash
+
+# vico-cli end-to-end smoke test
+# Builds the CLI, starts the bundled mock server and runs the main commands against it.
+# No network access or real credentials are required.
+
+set -euo pipefail
+
+ROOT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+WORK_DIR="$(mktemp -d)"
+PORT="${E2E_PORT:-18080}"
+BIN="${WORK_DIR}/vico-cli"
+
+cleanup() {
+    if [ -n "${SERVER_PID:-}" ]; then
+        kill "${SERVER_PID}" 2>/dev/null || true
+    fi
+    rm -rf "${WORK_DIR}"
+}
+trap cleanup EXIT
+
+# expect_output runs a command and fails if its output does not contain the expected text
+expect_output() {
+    local expected="$1"
+    shift
+    local output
+    output="$("$@" 2>&1)"
+    if ! grep -qF -- "${expected}" <<<"${output}"; then
+        echo "FAIL: $*"
+        echo "  expected output to contain: ${expected}"
+        echo "${output}" | sed 's/^/  | /'
+        exit 1
+    fi
+    echo "PASS: $*"
+}
+
+# expect_failure runs a command and fails unless it exits with the expected code and
+# its output contains the expected text
+expect_failure() {
+    local code="$1"
+    local expected="$2"
+    shift 2
+    local output
+    local status=0
+    output="$("$@" 2>&1)" || status=$?
+    if [ "${status}" -ne "${code}" ] || ! grep -qF -- "${expected}" <<<"${output}"; then
+        echo "FAIL: $*"
+        echo "  expected exit code ${code} and output containing: ${expected}"
+        echo "  got exit code ${status}"
+        echo "${output}" | sed 's/^/  | /'
+        exit 1
+    fi
+    echo "PASS: $* (exit ${code})"
+}
+
+cd "${ROOT_DIR}"
+go build -o "${BIN}" main.go
+
+# Use an isolated home directory so the real token cache is never touched
+export HOME="${WORK_DIR}/home"
+mkdir -p "${HOME}"
+
+# Fail the first request with 503 to exercise retries, and the first authenticated
+# request to exercise the token refresh path
+"${BIN}" mock-server --addr "127.0.0.1:${PORT}" --fail-http-count 1 \
+    --fail-auth-code -1027 --fail-auth-count 1 >/dev/null &
+SERVER_PID=$!
+sleep 1
+
+export VICOHOME_EMAIL="demo@example.com"
+export VICOHOME_PASSWORD="demo"
+export VICOHOME_API_URL="http://127.0.0.1:${PORT}"
+
+expect_output "Birdy House" "${BIN}" devices list
+expect_output "\"serialNumber\": \"378b660598295ceca8b20871991a0409\"" "${BIN}" devices list --format json
+expect_output "Signal Strength: -51 dBm" "${BIN}" devices get 378b660598295ceca8b20871991a0409
+expect_output "Northern Cardinal" "${BIN}" events list
+expect_output "Eastern Bluebird" "${BIN}" events search --field birdName bluebird
+
+TRACE_ID="$("${BIN}" events list --format json | grep -m1 '"traceId"' | cut -d'"' -f4)"
+expect_output "Trace ID:       ${TRACE_ID}" "${BIN}" events get "${TRACE_ID}"
+
+expect_output "Downloaded 3 files" "${BIN}" events download "${TRACE_ID}" --download-dir "${WORK_DIR}/media"
+expect_output "skipped 3 existing files" "${BIN}" events download "${TRACE_ID}" --download-dir "${WORK_DIR}/media"
+
+# Record a session including the login, then replay it without the server
+expect_output "Birdy House" env HOME="${WORK_DIR}/record" "${BIN}" devices list --record "${WORK_DIR}/recording"
+if grep -rqF -e "${VICOHOME_EMAIL}" -e "\"${VICOHOME_PASSWORD}\"" "${WORK_DIR}/recording"; then
+    echo "FAIL: recording contains credentials"
+    exit 1
+fi
+echo "PASS: recording is sanitized"
+expect_output "Birdy House" "${BIN}" devices list --replay "${WORK_DIR}/recording" --api-url "http://127.0.0.1:1"
+
+# Log every request, including the login, as JSON and make sure no credentials leak
+HOME="${WORK_DIR}/logs" "${BIN}" devices list --log-level debug --log-format json 2>"${WORK_DIR}/log.json" >/dev/null
+if ! grep -qF '"endpoint":"/account/login"' "${WORK_DIR}/log.json" ||
+    ! grep -qF '"endpoint":"/device/listuserdevices"' "${WORK_DIR}/log.json"; then
+    echo "FAIL: request log is missing API calls"
+    sed 's/^/  | /' "${WORK_DIR}/log.json"
+    exit 1
+fi
+if grep -qF -e "${VICOHOME_EMAIL}" -e "\"${VICOHOME_PASSWORD}\"" "${WORK_DIR}/log.json"; then
+    echo "FAIL: request log contains credentials"
+    exit 1
+fi
+echo "PASS: request log is structured and sanitized"
+
+expect_failure 3 "device nope not found" "${BIN}" devices get nope
+expect_failure 3 "event nope not found" "${BIN}" events get nope
+VICOHOME_PASSWORD="wrong" expect_failure 2 "account or password error" env HOME="${WORK_DIR}/other" "${BIN}" devices list
+expect_failure 5 "connection refused" "${BIN}" devices list --api-url "http://127.0.0.1:1"
+# The rate limit delays the second media file beyond the time limit
+expect_failure 5 "deadline exceeded" "${BIN}" events download "${TRACE_ID}" --download-dir "${WORK_DIR}/slow" --rate-limit 0.1 --timeout 1s
+
+echo "All end-to-end checks passed."